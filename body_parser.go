@@ -7,10 +7,39 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 )
 
+// autoBodyParserName is the name used by `body` tags to select a parser based
+// on the request's Content-Type.
+const autoBodyParserName = "auto"
+
+// BodyParser represents a format that can be used to parse request bodies
+// through `body:"name"` tags, where name is the value used to register the
+// parser through RegisterBodyParser or Mux.RegisterBodyParser.
+type BodyParser struct {
+	// ValidateType is invoked when a handler is registered, with the type of
+	// the field using the parser. Returning an error prevents the handler from
+	// being registered. When nil, structs, slices, maps and pointers are
+	// accepted.
+	ValidateType func(t reflect.Type) error
+
+	// MediaTypes lists media types (such as "application/yaml") handled by the
+	// parser. Fields using `body:"auto"` select a parser by matching those
+	// against the request's Content-Type.
+	MediaTypes []string
+
+	// Decode is invoked for each incoming request with a pointer to a new
+	// value of the field's type, which must be populated using the request's
+	// body. Returning an error causes a validation error to be reported.
+	Decode func(r *http.Request, into interface{}) error
+}
+
 type bodyParser struct {
 	typeName      string
+	mediaTypes    []string
 	handler       func(r *http.Request, into reflect.Type) (reflect.Value, error)
 	typeValidator func(t reflect.Type) error
 }
@@ -41,6 +70,7 @@ func specificTypeValidator(name string, kind reflect.Type) func(t reflect.Type)
 
 var jsonBodyParser = bodyParser{
 	typeName:      "json",
+	mediaTypes:    []string{"application/json"},
 	typeValidator: unmarshalerTypeValidator("json"),
 	handler: func(r *http.Request, into reflect.Type) (reflect.Value, error) {
 		inst := reflect.New(into)
@@ -50,12 +80,13 @@ var jsonBodyParser = bodyParser{
 			return reflect.Value{}, err
 		}
 
-		return inst, nil
+		return inst.Elem(), nil
 	},
 }
 
 var xmlBodyParser = bodyParser{
 	typeName:      "xml",
+	mediaTypes:    []string{"application/xml", "text/xml"},
 	typeValidator: unmarshalerTypeValidator("xml"),
 	handler: func(r *http.Request, into reflect.Type) (reflect.Value, error) {
 		inst := reflect.New(into)
@@ -65,12 +96,13 @@ var xmlBodyParser = bodyParser{
 			return reflect.Value{}, err
 		}
 
-		return inst, nil
+		return inst.Elem(), nil
 	},
 }
 
 var textBodyParser = bodyParser{
 	typeName:      "text",
+	mediaTypes:    []string{"text/plain"},
 	typeValidator: specificTypeValidator("text", reflect.TypeOf("")),
 	handler: func(r *http.Request, _ reflect.Type) (reflect.Value, error) {
 		bytes, err := io.ReadAll(r.Body)
@@ -82,7 +114,8 @@ var textBodyParser = bodyParser{
 }
 
 var bytesBodyParser = bodyParser{
-	typeName: "bytes",
+	typeName:   "bytes",
+	mediaTypes: []string{"application/octet-stream"},
 	typeValidator: func(t reflect.Type) error {
 		err := fmt.Errorf("cannot use bytes body on type %s. Expected a slice of bytes ([]byte)", t)
 		if t.Kind() != reflect.Slice {
@@ -115,27 +148,184 @@ var streamBodyParser = bodyParser{
 	},
 }
 
-var bodyParsers = map[string]bodyParser{
-	"json":   jsonBodyParser,
-	"xml":    xmlBodyParser,
-	"text":   textBodyParser,
-	"stream": streamBodyParser,
-	"bytes":  bytesBodyParser,
+var (
+	bodyParsersLock sync.RWMutex
+	bodyParsers     = map[string]bodyParser{
+		"json":   jsonBodyParser,
+		"xml":    xmlBodyParser,
+		"text":   textBodyParser,
+		"stream": streamBodyParser,
+		"bytes":  bytesBodyParser,
+	}
+)
+
+func makeBodyParser(name string, parser BodyParser) bodyParser {
+	if name == "" {
+		panic("raggett: RegisterBodyParser called with an empty name")
+	}
+	if name == autoBodyParserName {
+		panic("raggett: RegisterBodyParser cannot register a parser named " + autoBodyParserName)
+	}
+	if parser.Decode == nil {
+		panic("raggett: RegisterBodyParser called with a nil Decode function for " + name)
+	}
+
+	validator := parser.ValidateType
+	if validator == nil {
+		validator = unmarshalerTypeValidator(name)
+	}
+
+	mediaTypes := make([]string, 0, len(parser.MediaTypes))
+	for _, m := range parser.MediaTypes {
+		mediaTypes = append(mediaTypes, strings.ToLower(m))
+	}
+
+	return bodyParser{
+		typeName:      name,
+		mediaTypes:    mediaTypes,
+		typeValidator: validator,
+		handler: func(r *http.Request, into reflect.Type) (reflect.Value, error) {
+			inst := reflect.New(into)
+			if err := parser.Decode(r, inst.Interface()); err != nil {
+				return reflect.Value{}, err
+			}
+			return inst.Elem(), nil
+		},
+	}
+}
+
+// RegisterBodyParser registers a BodyParser under the provided name, making
+// it available to all Mux instances through `body:"name"` tags. Registering a
+// parser with the name of an existing one replaces it, including built-in
+// parsers. Parsers must be registered before handlers using them.
+// Panics in case name is empty, or parser does not provide a Decode function.
+func RegisterBodyParser(name string, parser BodyParser) {
+	p := makeBodyParser(name, parser)
+	bodyParsersLock.Lock()
+	defer bodyParsersLock.Unlock()
+	bodyParsers[name] = p
+}
+
+// RegisterBodyParser registers a BodyParser under the provided name, making it
+// available through `body:"name"` tags to handlers registered on this Mux and
+// Muxes derived from it afterwards. Parsers registered on a Mux take precedence
+// over parsers registered through the package-level RegisterBodyParser.
+// Panics in case name is empty, or parser does not provide a Decode function.
+func (mx *Mux) RegisterBodyParser(name string, parser BodyParser) {
+	p := makeBodyParser(name, parser)
+	// Copy the registry so parsers registered on derived Muxes do not leak
+	// into their parents.
+	parsers := make(map[string]bodyParser, len(mx.bodyParsers)+1)
+	for k, v := range mx.bodyParsers {
+		parsers[k] = v
+	}
+	parsers[name] = p
+	mx.bodyParsers = parsers
+}
+
+func (mx *Mux) bodyParserNamed(name string) (bodyParser, bool) {
+	if mx != nil {
+		if p, ok := mx.bodyParsers[name]; ok {
+			return p, true
+		}
+	}
+	bodyParsersLock.RLock()
+	defer bodyParsersLock.RUnlock()
+	p, ok := bodyParsers[name]
+	return p, ok
+}
+
+// autoBodyParser returns a bodyParser selecting one of the available parsers
+// accepting the provided type based on the request's Content-Type. Requests
+// using a Content-Type not handled by any of those parsers fail with
+// errUnsupportedMediaType. Returns false in case no parser accepts the type.
+// Parsers registered on the Mux take precedence over global ones, and when
+// more than one parser handles a media type, the first one by name is used.
+func (mx *Mux) autoBodyParser(t reflect.Type) (bodyParser, bool) {
+	parsers := map[string]bodyParser{}
+	bodyParsersLock.RLock()
+	for k, v := range bodyParsers {
+		parsers[k] = v
+	}
+	bodyParsersLock.RUnlock()
+	if mx != nil {
+		for k, v := range mx.bodyParsers {
+			parsers[k] = v
+		}
+	}
+
+	names := make([]string, 0, len(parsers))
+	for k := range parsers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	byMediaType := map[string]bodyParser{}
+	for _, name := range names {
+		p := parsers[name]
+		if len(p.mediaTypes) == 0 || p.typeValidator(t) != nil {
+			continue
+		}
+		for _, m := range p.mediaTypes {
+			if _, ok := byMediaType[m]; !ok {
+				byMediaType[m] = p
+			}
+		}
+	}
+
+	if len(byMediaType) == 0 {
+		return bodyParser{}, false
+	}
+
+	mediaTypes := make([]string, 0, len(byMediaType))
+	for m := range byMediaType {
+		mediaTypes = append(mediaTypes, m)
+	}
+	sort.Strings(mediaTypes)
+
+	return bodyParser{
+		typeName:   autoBodyParserName,
+		mediaTypes: mediaTypes,
+		handler: func(r *http.Request, into reflect.Type) (reflect.Value, error) {
+			p, ok := parserForContentType(byMediaType, r.Header.Get("Content-Type"))
+			if !ok {
+				return reflect.Value{}, errUnsupportedMediaType
+			}
+			return p.handler(r, into)
+		},
+	}, true
+}
+
+// parserForContentType parses the provided Content-Type value and returns the
+// parser registered for it. Structured syntax suffixes are also considered,
+// so "application/vnd.example+json" is handled by the "application/json"
+// parser, unless the vendored type is registered itself.
+func parserForContentType(parsers map[string]bodyParser, contentType string) (bodyParser, bool) {
+	ok, types := parseAcceptHeader(contentType)
+	if !ok || len(types) != 1 {
+		return bodyParser{}, false
+	}
+
+	mt := types[0]
+	if p, ok := parsers[mt.Type()]; ok {
+		return p, true
+	}
+
+	if idx := strings.LastIndex(mt.SubTypeString, "+"); idx != -1 {
+		p, ok := parsers[mt.TypeString+"/"+mt.SubTypeString[idx+1:]]
+		return p, ok
+	}
+
+	return bodyParser{}, false
 }
 
 func handleBodyParsing(meta *handlerMetadata, r *Request, instance reflect.Value) error {
 	strField := meta.body.structField
-	// get handler
-	handler := bodyParsers[meta.bodyKind]
-	v, err := handler.handler(r.HTTPRequest, meta.body.structField.Type)
+	v, err := meta.bodyParser.handler(r.HTTPRequest, strField.Type)
 	if err != nil {
 		return err
 	}
 
-	if k := strField.Type.Kind(); k == reflect.Struct {
-		v = v.Elem()
-	}
-
 	instance.FieldByIndex(strField.Index).Set(v)
 	return nil
 }
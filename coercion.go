@@ -0,0 +1,171 @@
+package raggett
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// coercer parses a single string value into a provided reflect.Value, which
+// must be settable.
+type coercer func(val string, into reflect.Value) error
+
+var timeType = reflect.TypeOf(time.Time{})
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+var urlPtrType = reflect.TypeOf(&url.URL{})
+
+// coercerForType returns a coercer capable of parsing values into the provided
+// type, using layout to parse time.Time values. Returns false in case the type
+// is not supported.
+func coercerForType(t reflect.Type, layout string) (coercer, bool) {
+	if t.Kind() == reflect.Ptr && t != urlPtrType {
+		// Pointers are only allocated when a value is present, allowing
+		// handlers to distinguish absent values from zero values.
+		elem := t.Elem()
+		inner, ok := coercerForType(elem, layout)
+		if !ok {
+			return nil, false
+		}
+		return func(val string, into reflect.Value) error {
+			v := reflect.New(elem)
+			if err := inner(val, v.Elem()); err != nil {
+				return err
+			}
+			into.Set(v)
+			return nil
+		}, true
+	}
+
+	switch {
+	case t == timeType:
+		return func(val string, into reflect.Value) error {
+			v, err := time.Parse(layout, val)
+			if err != nil {
+				return err
+			}
+			into.Set(reflect.ValueOf(v))
+			return nil
+		}, true
+	case t == durationType:
+		return func(val string, into reflect.Value) error {
+			v, err := time.ParseDuration(val)
+			if err != nil {
+				return err
+			}
+			into.SetInt(int64(v))
+			return nil
+		}, true
+	case t == urlPtrType:
+		return func(val string, into reflect.Value) error {
+			v, err := url.Parse(val)
+			if err != nil {
+				return err
+			}
+			into.Set(reflect.ValueOf(v))
+			return nil
+		}, true
+	case reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return func(val string, into reflect.Value) error {
+			return into.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val))
+		}, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.String:
+		kind := t.Kind()
+		return func(val string, into reflect.Value) error {
+			return doPrimitiveCoercion(val, kind, into)
+		}, true
+	}
+
+	return nil, false
+}
+
+// resolveCoercer determines how values for a given field are parsed, setting
+// its coercer and whether it receives multiple values. Returns an error in
+// case the field's type is not supported.
+func resolveCoercer(input reflect.Type, field *requestField, format string, hasFormat bool) error {
+	t := field.structField.Type
+	isTime := t == timeType || ((t.Kind() == reflect.Slice || t.Kind() == reflect.Map) && t.Elem() == timeType)
+	if hasFormat && (format == "" || !isTime) {
+		return errInvalidFormatTag(input, *field.structField)
+	}
+
+	layout := time.RFC3339
+	if hasFormat {
+		layout = format
+	}
+
+	if c, ok := coercerForType(t, layout); ok {
+		field.coercer = c
+		return nil
+	}
+
+	if t.Kind() == reflect.Slice {
+		if c, ok := coercerForType(t.Elem(), layout); ok {
+			field.coercer = c
+			field.isSlice = true
+			return nil
+		}
+	}
+
+	if t.Kind() == reflect.Map && t.Key().Kind() == reflect.String {
+		if c, ok := coercerForType(t.Elem(), layout); ok {
+			field.coercer = c
+			field.isMap = true
+			return nil
+		}
+	}
+
+	return errUnsupportedFieldType(input, *field.structField, t)
+}
+
+// resolveSplit configures a slice field to receive values as lists separated
+// by sep, such as "1,2,3".
+func resolveSplit(input reflect.Type, field *requestField, sep string) error {
+	if sep == "" || !field.isSlice {
+		return errInvalidSplitTag(input, *field.structField)
+	}
+	field.split = sep
+	return nil
+}
+
+// splitValues splits the provided values using the field's separator, if
+// any. Empty items are discarded.
+func (f *requestField) splitValues(values []string) []string {
+	if f.split == "" {
+		return values
+	}
+	var result []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, f.split) {
+			if item != "" {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
+// resolveDefault validates the provided default value against the field's
+// type, storing it to be used when the field is absent from a request.
+func resolveDefault(input reflect.Type, field *requestField, value string) error {
+	if field.isMap {
+		return errInvalidDefault(input, *field.structField, errMapDefaultNotSupported)
+	}
+	t := field.structField.Type
+	if field.isSlice {
+		t = t.Elem()
+	}
+	values := field.splitValues([]string{value})
+	for _, v := range values {
+		if err := field.coercer(v, reflect.New(t).Elem()); err != nil {
+			return errInvalidDefault(input, *field.structField, err)
+		}
+	}
+	field.defaultValue = values
+	return nil
+}
@@ -0,0 +1,264 @@
+package raggett
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var constraintTags = []string{"min", "max", "minlen", "maxlen", "oneof", "minitems", "maxitems"}
+
+// fieldConstraints holds validations defined through the `min`, `max`,
+// `minlen`, `maxlen`, `oneof`, `minitems` and `maxitems` tags of a field.
+type fieldConstraints struct {
+	min, max           *float64
+	minLen, maxLen     *int
+	minItems, maxItems *int
+	oneOf              []string
+	oneOfValues        []reflect.Value
+
+	// Raw tag values, used to describe constraints to clients.
+	rawMin, rawMax string
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// parseNumericBound parses the value of a `min` or `max` tag for a given type.
+// time.Duration fields accept values such as "1m30s".
+func parseNumericBound(t reflect.Type, tag, value string) (*float64, error) {
+	if t == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tag, err)
+		}
+		v := float64(d)
+		return &v, nil
+	}
+	if !isNumericKind(t.Kind()) {
+		return nil, fmt.Errorf("%s can only be used with numeric fields", tag)
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tag, err)
+	}
+	return &v, nil
+}
+
+func parseCountBound(tag, value string) (*int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", tag, err)
+	}
+	if v < 0 {
+		return nil, fmt.Errorf("%s must not be negative", tag)
+	}
+	return &v, nil
+}
+
+// resolveConstraints parses validation tags of a given field, ensuring they
+// are compatible with its type. Must be called after resolveCoercer.
+func resolveConstraints(input reflect.Type, field *requestField) error {
+	tags := field.structField.Tag
+	t := field.structField.Type
+	if field.isSlice || field.isMap {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Ptr && t != urlPtrType {
+		t = t.Elem()
+	}
+
+	c := &field.constraints
+	fail := func(err error) error {
+		return errInvalidConstraint(input, *field.structField, err)
+	}
+
+	var err error
+	if v, ok := tags.Lookup("min"); ok {
+		if c.min, err = parseNumericBound(t, "min", v); err != nil {
+			return fail(err)
+		}
+		c.rawMin = v
+	}
+	if v, ok := tags.Lookup("max"); ok {
+		if c.max, err = parseNumericBound(t, "max", v); err != nil {
+			return fail(err)
+		}
+		c.rawMax = v
+	}
+	if c.min != nil && c.max != nil && *c.min > *c.max {
+		return fail(fmt.Errorf("min must not be greater than max"))
+	}
+
+	for _, tag := range []string{"minlen", "maxlen"} {
+		v, ok := tags.Lookup(tag)
+		if !ok {
+			continue
+		}
+		if t.Kind() != reflect.String {
+			return fail(fmt.Errorf("%s can only be used with string fields", tag))
+		}
+		bound, err := parseCountBound(tag, v)
+		if err != nil {
+			return fail(err)
+		}
+		if tag == "minlen" {
+			c.minLen = bound
+		} else {
+			c.maxLen = bound
+		}
+	}
+	if c.minLen != nil && c.maxLen != nil && *c.minLen > *c.maxLen {
+		return fail(fmt.Errorf("minlen must not be greater than maxlen"))
+	}
+
+	for _, tag := range []string{"minitems", "maxitems"} {
+		v, ok := tags.Lookup(tag)
+		if !ok {
+			continue
+		}
+		if !field.isSlice && !field.isMap {
+			return fail(fmt.Errorf("%s can only be used with slice and map fields", tag))
+		}
+		bound, err := parseCountBound(tag, v)
+		if err != nil {
+			return fail(err)
+		}
+		if tag == "minitems" {
+			c.minItems = bound
+		} else {
+			c.maxItems = bound
+		}
+	}
+	if c.minItems != nil && c.maxItems != nil && *c.minItems > *c.maxItems {
+		return fail(fmt.Errorf("minitems must not be greater than maxitems"))
+	}
+
+	if v, ok := tags.Lookup("oneof"); ok {
+		if v == "" {
+			return fail(fmt.Errorf("oneof must list at least one value"))
+		}
+		elemType := field.structField.Type
+		if field.isSlice || field.isMap {
+			elemType = elemType.Elem()
+		}
+		for _, opt := range strings.Split(v, "|") {
+			parsed := reflect.New(elemType).Elem()
+			if err := field.coercer(opt, parsed); err != nil {
+				return fail(fmt.Errorf("oneof: value %q is invalid: %w", opt, err))
+			}
+			c.oneOf = append(c.oneOf, opt)
+			c.oneOfValues = append(c.oneOfValues, reflect.Indirect(parsed))
+		}
+	}
+
+	return nil
+}
+
+// numericValue returns the value of a numeric reflect.Value as a float64.
+func numericValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+// check validates a coerced field value against the constraints, returning the
+// kind of the failed validation, and a description of the violated
+// constraint.
+func (c *fieldConstraints) check(field *requestField, value reflect.Value) (ValidationErrorKind, string, bool) {
+	if field.isMap {
+		if kind, constraint, ok := c.checkItems(value); !ok {
+			return kind, constraint, ok
+		}
+		for _, k := range sortedMapKeys(value) {
+			if kind, constraint, ok := c.checkValue(value.MapIndex(k)); !ok {
+				return kind, constraint, ok
+			}
+		}
+		return 0, "", true
+	}
+	if field.isSlice {
+		if kind, constraint, ok := c.checkItems(value); !ok {
+			return kind, constraint, ok
+		}
+		for i := 0; i < value.Len(); i++ {
+			if kind, constraint, ok := c.checkValue(value.Index(i)); !ok {
+				return kind, constraint, ok
+			}
+		}
+		return 0, "", true
+	}
+	return c.checkValue(value)
+}
+
+// checkItems validates the amount of items of a slice or map.
+func (c *fieldConstraints) checkItems(value reflect.Value) (ValidationErrorKind, string, bool) {
+	if c.minItems != nil && value.Len() < *c.minItems {
+		return ValidationErrorKindMinItems, fmt.Sprintf("minitems=%d", *c.minItems), false
+	}
+	if c.maxItems != nil && value.Len() > *c.maxItems {
+		return ValidationErrorKindMaxItems, fmt.Sprintf("maxitems=%d", *c.maxItems), false
+	}
+	return 0, "", true
+}
+
+func (c *fieldConstraints) checkValue(value reflect.Value) (ValidationErrorKind, string, bool) {
+	for value.Kind() == reflect.Ptr && value.Type() != urlPtrType {
+		if value.IsNil() {
+			return 0, "", true
+		}
+		value = value.Elem()
+	}
+
+	if c.min != nil && numericValue(value) < *c.min {
+		return ValidationErrorKindMin, "min=" + c.rawMin, false
+	}
+	if c.max != nil && numericValue(value) > *c.max {
+		return ValidationErrorKindMax, "max=" + c.rawMax, false
+	}
+	if c.minLen != nil || c.maxLen != nil {
+		length := utf8.RuneCountInString(value.String())
+		if c.minLen != nil && length < *c.minLen {
+			return ValidationErrorKindMinLength, fmt.Sprintf("minlen=%d", *c.minLen), false
+		}
+		if c.maxLen != nil && length > *c.maxLen {
+			return ValidationErrorKindMaxLength, fmt.Sprintf("maxlen=%d", *c.maxLen), false
+		}
+	}
+	if c.oneOfValues != nil {
+		found := false
+		for _, opt := range c.oneOfValues {
+			if reflect.DeepEqual(opt.Interface(), value.Interface()) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ValidationErrorKindOneOf, "oneof=" + strings.Join(c.oneOf, "|"), false
+		}
+	}
+	return 0, "", true
+}
+
+// sortedMapKeys returns the keys of a map with string keys in sorted order.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	return keys
+}
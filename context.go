@@ -0,0 +1,118 @@
+package raggett
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ContextKey represents a typed key for values stored in request contexts,
+// usually by middlewares. Request structs can receive values stored through a
+// ContextKey using the `context` tag along with the key's name. Like other
+// resolvers, values are optional unless `required:"true"` is provided:
+//
+//	var UserKey = raggett.NewContextKey[*User]("user")
+//
+//	type ProfileRequest struct {
+//	    *raggett.Request
+//	    User *User `context:"user" required:"true"`
+//	}
+type ContextKey[T any] struct {
+	name string
+}
+
+type registeredContextKey struct {
+	key       interface{}
+	valueType reflect.Type
+}
+
+var contextKeys = struct {
+	sync.RWMutex
+	keys map[string]registeredContextKey
+}{keys: map[string]registeredContextKey{}}
+
+// NewContextKey creates and registers a new ContextKey with a given name,
+// which can then be used by `context` tags. Panics in case another key with
+// the same name has already been registered.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	contextKeys.Lock()
+	defer contextKeys.Unlock()
+	if _, ok := contextKeys.keys[name]; ok {
+		panic(fmt.Sprintf("raggett: context key '%s' is already registered", name))
+	}
+	k := &ContextKey[T]{name: name}
+	contextKeys.keys[name] = registeredContextKey{
+		key:       k,
+		valueType: reflect.TypeOf((*T)(nil)).Elem(),
+	}
+	return k
+}
+
+// Name returns the name the key was registered with.
+func (k *ContextKey[T]) Name() string {
+	return k.name
+}
+
+// WithValue returns a copy of ctx associating the provided value with the key.
+func (k *ContextKey[T]) WithValue(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// Value returns the value associated with the key in ctx, along with a
+// boolean indicating whether it is present.
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+func (k *ContextKey[T]) String() string {
+	return "raggett.ContextKey(" + k.name + ")"
+}
+
+// resolveContextKey determines the key used to obtain values for a field using
+// the `context` tag. The name must have been registered through NewContextKey,
+// with a type assignable to the field.
+func resolveContextKey(input reflect.Type, field *requestField, name string) error {
+	contextKeys.RLock()
+	registered, ok := contextKeys.keys[name]
+	contextKeys.RUnlock()
+
+	if !ok {
+		return errInvalidContextField(input, *field.structField,
+			fmt.Errorf("no context key registered with name %s", name))
+	}
+
+	if !registered.valueType.AssignableTo(field.structField.Type) {
+		return errInvalidContextField(input, *field.structField,
+			fmt.Errorf("context key %s holds %s values", name, registered.valueType))
+	}
+	field.contextKey = registered.key
+	return nil
+}
+
+// applyContextValue loads a given field from the request's context. Missing
+// values are only reported for fields using `required:"true"`. Failures are
+// reported using the status defined by Mux.ContextValueErrorStatus.
+func applyContextValue(r *Request, field *requestField, inst reflect.Value) error {
+	fail := func(kind ValidationErrorKind, err error) error {
+		vErr := makeValidationErrorWithError(fieldKindContext, kind, field, err)
+		vErr.StatusCode = r.mux.ContextValueErrorStatus
+		return vErr
+	}
+
+	value := r.HTTPRequest.Context().Value(field.contextKey)
+	if value == nil {
+		if field.required != nil && *field.required {
+			return fail(ValidationErrorKindRequired, nil)
+		}
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(field.structField.Type) {
+		return fail(ValidationErrorKindType, fmt.Errorf("expected %s, found %s", field.structField.Type, v.Type()))
+	}
+	inst.FieldByIndex(field.structField.Index).Set(v)
+	return nil
+}
@@ -0,0 +1,144 @@
+package raggett
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type contextTestUser struct {
+	Name string
+}
+
+var (
+	contextTestUserKey   = NewContextKey[*contextTestUser]("test_user")
+	contextTestTenantKey = NewContextKey[string]("test_tenant")
+)
+
+func TestContextKey(t *testing.T) {
+	ctx := contextTestUserKey.WithValue(context.Background(), &contextTestUser{Name: "Paul"})
+	u, ok := contextTestUserKey.Value(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "Paul", u.Name)
+
+	_, ok = contextTestUserKey.Value(context.Background())
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { NewContextKey[string]("test_user") })
+}
+
+func TestContextLoader(t *testing.T) {
+	type request struct {
+		*Request
+		User   *contextTestUser `context:"test_user" required:"true"`
+		Tenant string           `context:"test_tenant"`
+	}
+
+	var received *request
+	mx := NewMux(zap.NewNop())
+	mx.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			switch r.Header.Get("X-User") {
+			case "valid":
+				ctx = contextTestUserKey.WithValue(ctx, &contextTestUser{Name: "Paul"})
+				ctx = contextTestTenantKey.WithValue(ctx, "acme")
+			case "anonymous":
+				ctx = contextTestTenantKey.WithValue(ctx, "acme")
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	mx.Get("/", func(r *request) error {
+		received = r
+		return nil
+	})
+
+	serve := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("valid")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	require.NotNil(t, received)
+	assert.Equal(t, "Paul", received.User.Name)
+	assert.Equal(t, "acme", received.Tenant)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("anonymous").Code)
+
+	mx.ContextValueErrorStatus = http.StatusForbidden
+	assert.Equal(t, http.StatusForbidden, serve("").Code)
+}
+
+func TestContextLoaderErrors(t *testing.T) {
+	type request struct {
+		*Request
+		User   *contextTestUser `context:"test_user" required:"true"`
+		Tenant string           `context:"test_tenant"`
+	}
+
+	r := httptest.NewRequest("POST", "/", nil)
+	ctx := contextTestUserKey.WithValue(r.Context(), &contextTestUser{})
+	r = r.WithContext(context.WithValue(ctx, contextTestTenantKey, 10))
+
+	_, validationError, _ := testMuxPostWith(t, r, "/", func(req *request) error {
+		return nil
+	})
+	require.Error(t, validationError)
+	vErr := validationError.(ValidationError)
+	assert.Equal(t, "test_tenant", vErr.FieldName)
+	assert.Equal(t, fieldKindContext, vErr.FieldKind)
+	assert.Equal(t, ValidationErrorKindType, vErr.ErrorKind)
+	assert.Equal(t, http.StatusUnauthorized, vErr.StatusCode)
+}
+
+func TestContextFieldErrors(t *testing.T) {
+	_, err := determineFuncParams(nil, func(r *struct {
+		*Request
+		User contextTestUser `context:"test_user"`
+	}) error {
+		return nil
+	})
+	assert.IsType(t, ErrInvalidContextField{}, err)
+
+	_, err = determineFuncParams(nil, func(r *struct {
+		*Request
+		Tenant string `context:"test_tenant" pattern:"^a$"`
+	}) error {
+		return nil
+	})
+	assert.IsType(t, ErrInvalidContextField{}, err)
+
+	_, err = determineFuncParams(nil, func(r *struct {
+		*Request
+		Tenant string `context:"tenant"`
+	}) error {
+		return nil
+	})
+	assert.IsType(t, ErrInvalidContextField{}, err)
+}
+
+func TestContextLoaderOptional(t *testing.T) {
+	type request struct {
+		*Request
+		User *contextTestUser `context:"test_user"`
+	}
+
+	r := httptest.NewRequest("POST", "/", nil)
+	_, validationError, runtimeError := testMuxPostWith(t, r, "/", func(req *request) error {
+		assert.Nil(t, req.User)
+		return nil
+	})
+	assert.NoError(t, validationError)
+	assert.NoError(t, runtimeError)
+}
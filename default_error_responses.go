@@ -13,9 +13,11 @@ import (
 	"github.com/heyvito/raggett/templates"
 )
 
-type oneToManyMap map[string][]string
+// OneToManyMap maps names to multiple values, such as headers and query
+// string values provided by a request.
+type OneToManyMap map[string][]string
 
-func (otm oneToManyMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+func (otm OneToManyMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	type oneToMany struct {
 		XMLName xml.Name `xml:"item"`
 		Name    string   `xml:"name"`
@@ -35,9 +37,10 @@ func (otm oneToManyMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error
 	return e.EncodeElement(oneToManyVal, start)
 }
 
-type oneToOneMap map[string]string
+// OneToOneMap maps names to a single value, such as environment variables.
+type OneToOneMap map[string]string
 
-func (oto oneToOneMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+func (oto OneToOneMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	type oneToMany struct {
 		XMLName xml.Name `xml:"item"`
 		Name    string   `xml:"name"`
@@ -59,7 +62,9 @@ func (oto oneToOneMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error
 	return e.EncodeElement(oneToManyWrapper{Items: oneToManyVal}, start)
 }
 
-type errorTemplate struct {
+// ErrorTemplateData is passed to the ServerErrorHTML and ServerErrorText
+// templates, used to render runtime errors when Mux.Development is set.
+type ErrorTemplateData struct {
 	XMLName        xml.Name     `json:"-" xml:"error"`
 	Code           int          `json:"code,omitempty" xml:"code"`
 	StatusName     string       `json:"status_name,omitempty" xml:"status_name"`
@@ -68,13 +73,17 @@ type errorTemplate struct {
 	ErrorType      string       `json:"error_type,omitempty" xml:"error_type"`
 	ErrorPackage   string       `json:"error_package,omitempty" xml:"error_package"`
 	StackTrace     string       `json:"stack_trace,omitempty" xml:"stack_trace"`
-	Headers        oneToManyMap `json:"headers,omitempty" xml:"headers"`
-	RequestDetails requestInfo  `json:"request_details" xml:"request_details"`
-	Environment    oneToOneMap  `json:"environment,omitempty" xml:"environments"`
+	Headers        OneToManyMap `json:"headers,omitempty" xml:"headers"`
+	RequestDetails RequestInfo  `json:"request_details" xml:"request_details"`
+	Environment    OneToOneMap  `json:"environment,omitempty" xml:"environments"`
 	Message        string       `json:"message,omitempty" xml:"message"`
 }
 
-type validationErrorTemplate struct {
+// ValidationErrorTemplateData is passed to the ValidationErrorHTML and
+// ValidationErrorText templates, used to render validation errors when
+// Mux.Development is set. Fields describing a single field refer to the first
+// error, while Errors lists every error.
+type ValidationErrorTemplateData struct {
 	XMLName        xml.Name     `json:"-" xml:"validation_error"`
 	Code           int          `json:"code,omitempty" xml:"code"`
 	StatusName     string       `json:"status_name,omitempty" xml:"status_name"`
@@ -83,9 +92,9 @@ type validationErrorTemplate struct {
 	ErrorType      string       `json:"error_type,omitempty" xml:"error_type"`
 	ErrorPackage   string       `json:"error_package,omitempty" xml:"error_package"`
 	Message        string       `json:"message,omitempty" xml:"message"`
-	Headers        oneToManyMap `json:"headers,omitempty" xml:"headers"`
-	RequestDetails requestInfo  `json:"request_details" xml:"request_details"`
-	Environment    oneToOneMap  `json:"environment,omitempty" xml:"environments"`
+	Headers        OneToManyMap `json:"headers,omitempty" xml:"headers"`
+	RequestDetails RequestInfo  `json:"request_details" xml:"request_details"`
+	Environment    OneToOneMap  `json:"environment,omitempty" xml:"environments"`
 
 	StructName       string `json:"struct_name,omitempty" xml:"struct_name"`
 	StructField      string `json:"struct_field,omitempty" xml:"struct_field"`
@@ -93,106 +102,179 @@ type validationErrorTemplate struct {
 	FieldSource      string `json:"field_source,omitempty" xml:"field_source"`
 	ErrorKind        string `json:"error_kind,omitempty" xml:"error_kind"`
 	OriginalError    string `json:"original_error,omitempty" xml:"original_error"`
+
+	Errors []ValidationErrorDetail `json:"errors,omitempty" xml:"errors>error"`
+}
+
+// ValidationErrorDetail describes a single ValidationError.
+type ValidationErrorDetail struct {
+	Message          string `json:"message,omitempty" xml:"message"`
+	StructName       string `json:"struct_name,omitempty" xml:"struct_name"`
+	StructField      string `json:"struct_field,omitempty" xml:"struct_field"`
+	RequestFieldName string `json:"request_field_name,omitempty" xml:"request_field_name"`
+	FieldSource      string `json:"field_source,omitempty" xml:"field_source"`
+	ErrorKind        string `json:"error_kind,omitempty" xml:"error_kind"`
+	Constraint       string `json:"constraint,omitempty" xml:"constraint,omitempty"`
+	OriginalError    string `json:"original_error,omitempty" xml:"original_error"`
 }
 
-type constrainedValidationErrorTemplate struct {
-	Code      int    `json:"code,omitempty" xml:"code"`
-	Message   string `json:"message,omitempty" xml:"message"`
-	RequestID string `json:"request_id,omitempty" xml:"request_id"`
+// ConstrainedValidationErrorTemplateData is passed to the
+// ValidationErrorConstrainedHTML and ValidationErrorConstrainedText templates,
+// used to render validation errors when Mux.Development is not set.
+type ConstrainedValidationErrorTemplateData struct {
+	Code      int      `json:"code,omitempty" xml:"code"`
+	Message   string   `json:"message,omitempty" xml:"message"`
+	Errors    []string `json:"errors,omitempty" xml:"errors>error"`
+	RequestID string   `json:"request_id,omitempty" xml:"request_id"`
 }
 
-type constrainedErrorTemplate struct {
+// ConstrainedErrorTemplateData is passed to the ServerErrorConstrainedHTML and
+// ServerErrorConstrainedText templates, used to render runtime errors when
+// Mux.Development is not set. Message is only set for HTTPError values and
+// mapped errors.
+type ConstrainedErrorTemplateData struct {
 	Code       int    `json:"code,omitempty" xml:"code"`
 	StatusName string `json:"status_name,omitempty" xml:"status_name"`
+	Message    string `json:"message,omitempty" xml:"message,omitempty"`
 	RequestID  string `json:"request_id,omitempty" xml:"request_id"`
 }
 
-type requestInfo struct {
-	Queries oneToManyMap `json:"queries,omitempty" xml:"queries"`
-	Form    oneToManyMap `json:"form,omitempty" xml:"form"`
-	Files   oneToManyMap `json:"files,omitempty" xml:"files"`
+// RequestInfo contains values provided by a request.
+type RequestInfo struct {
+	Queries OneToManyMap `json:"queries,omitempty" xml:"queries"`
+	Form    OneToManyMap `json:"form,omitempty" xml:"form"`
+	Files   OneToManyMap `json:"files,omitempty" xml:"files"`
+	Cookies OneToManyMap `json:"cookies,omitempty" xml:"cookies"`
+}
+
+// requestCookies returns cookies sent by the client, indexed by name.
+func requestCookies(r *http.Request) OneToManyMap {
+	cookies := r.Cookies()
+	if len(cookies) == 0 {
+		return nil
+	}
+	result := OneToManyMap{}
+	for _, c := range cookies {
+		result[c.Name] = append(result[c.Name], c.Value)
+	}
+	return result
 }
 
-type notFoundTemplate struct {
+// NotFoundTemplateData is passed to the NotFoundErrorHTML and
+// NotFoundErrorText templates, used to render Not Found and Method Not Allowed
+// errors when Mux.Development is set.
+type NotFoundTemplateData struct {
 	XMLName        xml.Name      `json:"-" xml:"error"`
 	Code           int           `json:"code,omitempty" xml:"code"`
 	StatusName     string        `json:"status_name,omitempty" xml:"status_name"`
 	Method         string        `json:"method,omitempty" xml:"method"`
 	Path           string        `json:"path,omitempty" xml:"path"`
-	Headers        oneToManyMap  `json:"headers,omitempty" xml:"headers"`
-	RequestDetails requestInfo   `json:"request_details" xml:"request_details"`
-	Environment    oneToOneMap   `json:"environment,omitempty" xml:"environments"`
-	Routes         routeInfoList `json:"routes,omitempty" xml:"routes"`
+	Headers        OneToManyMap  `json:"headers,omitempty" xml:"headers"`
+	RequestDetails RequestInfo   `json:"request_details" xml:"request_details"`
+	Environment    OneToOneMap   `json:"environment,omitempty" xml:"environments"`
+	Routes         RouteInfoList `json:"routes,omitempty" xml:"routes"`
 }
 
-type routeInfoList []routeInfo
+// RouteInfoList lists routes registered on a Mux.
+type RouteInfoList []RouteInfo
 
-func (r routeInfoList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+func (r RouteInfoList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	type routeInfoWrapper struct {
 		XMLName xml.Name    `xml:"routes"`
-		Route   []routeInfo `xml:"route"`
+		Route   []RouteInfo `xml:"route"`
 	}
 
 	return e.EncodeElement(routeInfoWrapper{Route: r}, start)
 }
 
-type routeInfo struct {
+// RouteInfo describes a route registered on a Mux.
+type RouteInfo struct {
 	Method  string `json:"method,omitempty" xml:"method"`
 	Pattern string `json:"pattern,omitempty" xml:"pattern"`
 	Handler string `json:"handler,omitempty" xml:"handler"`
 }
 
-type notFoundConstrainedTemplate struct {
+// ConstrainedNotFoundTemplateData is passed to the
+// NotFoundErrorConstrainedHTML and NotFoundErrorConstrainedText templates,
+// used to render Not Found and Method Not Allowed errors when Mux.Development
+// is not set.
+type ConstrainedNotFoundTemplateData struct {
 	XMLName    xml.Name `json:"-" xml:"not_found"`
 	Code       int      `json:"code,omitempty" xml:"code"`
 	Message    string   `json:"message,omitempty" xml:"message"`
 	StatusName string   `json:"status_name,omitempty" xml:"status_name"`
 }
 
-var notFoundConstrainedValue = notFoundConstrainedTemplate{
+var notFoundConstrainedValue = ConstrainedNotFoundTemplateData{
 	Code:       http.StatusNotFound,
 	StatusName: http.StatusText(http.StatusNotFound),
 	Message:    "The requested resource was not found.",
 }
 
-var methodNotAllowedConstrainedValue = notFoundConstrainedTemplate{
+var methodNotAllowedConstrainedValue = ConstrainedNotFoundTemplateData{
 	Code:       http.StatusMethodNotAllowed,
 	StatusName: http.StatusText(http.StatusMethodNotAllowed),
 	Message:    "This endpoint does not allow this HTTP method.",
 }
 
-func validationErrorToTemplate(r *Request, err ValidationError, status int) validationErrorTemplate {
+func validationErrorToDetail(err ValidationError) ValidationErrorDetail {
+	detail := ValidationErrorDetail{
+		Message:          err.Error(),
+		StructName:       err.StructName,
+		StructField:      err.StructFieldName,
+		RequestFieldName: err.FieldName,
+		FieldSource:      err.FieldKind.String(),
+		ErrorKind:        err.ErrorKind.Name(),
+		Constraint:       err.Constraint,
+	}
+
+	if err.OriginalError != nil {
+		detail.OriginalError = err.OriginalError.Error()
+	}
+
+	return detail
+}
+
+func validationErrorToTemplate(r *Request, errs ValidationErrors, status int) ValidationErrorTemplateData {
 	environment := map[string]string{}
 	for _, e := range os.Environ() {
 		comps := strings.SplitN(e, "=", 2)
 		environment[comps[0]] = comps[1]
 	}
 
-	errType := reflect.TypeOf(err)
+	errType := reflect.TypeOf(errs[0])
+	if len(errs) > 1 {
+		errType = reflect.TypeOf(errs)
+	}
+
+	details := make([]ValidationErrorDetail, 0, len(errs))
+	for _, e := range errs {
+		details = append(details, validationErrorToDetail(e))
+	}
+	first := details[0]
 
-	tmpl := validationErrorTemplate{
+	tmpl := ValidationErrorTemplateData{
 		Code:         status,
 		StatusName:   http.StatusText(status),
 		Method:       r.HTTPRequest.Method,
 		Path:         r.HTTPRequest.URL.Path,
 		ErrorType:    errType.Name(),
 		ErrorPackage: errType.PkgPath(),
-		Message:      err.Error(),
-		Headers:      oneToManyMap(r.HTTPRequest.Header),
-		RequestDetails: requestInfo{
-			Queries: oneToManyMap(r.HTTPRequest.URL.Query()),
-			Form:    oneToManyMap(r.HTTPRequest.PostForm),
+		Message:      errs.Error(),
+		Headers:      OneToManyMap(r.HTTPRequest.Header),
+		RequestDetails: RequestInfo{
+			Queries: OneToManyMap(r.HTTPRequest.URL.Query()),
+			Form:    OneToManyMap(r.HTTPRequest.PostForm),
+			Cookies: requestCookies(r.HTTPRequest),
 		},
 		Environment:      environment,
-		StructName:       err.StructName,
-		StructField:      err.StructFieldName,
-		RequestFieldName: err.FieldName,
-		FieldSource:      err.FieldKind.String(),
-		ErrorKind:        err.ErrorKind.Name(),
-	}
-
-	if err.OriginalError != nil {
-		tmpl.OriginalError = err.OriginalError.Error()
+		StructName:       first.StructName,
+		StructField:      first.StructField,
+		RequestFieldName: first.RequestFieldName,
+		FieldSource:      first.FieldSource,
+		ErrorKind:        first.ErrorKind,
+		OriginalError:    first.OriginalError,
+		Errors:           details,
 	}
 
 	if r.HTTPRequest.MultipartForm != nil {
@@ -210,30 +292,36 @@ func validationErrorToTemplate(r *Request, err ValidationError, status int) vali
 	return tmpl
 }
 
-func validationErrorToConstrainedTemplate(r *Request, err ValidationError, status int) constrainedValidationErrorTemplate {
-	return constrainedValidationErrorTemplate{
+func validationErrorToConstrainedTemplate(r *Request, errs ValidationErrors, status int) ConstrainedValidationErrorTemplateData {
+	messages := make([]string, 0, len(errs))
+	for _, e := range errs {
+		messages = append(messages, e.Error())
+	}
+	return ConstrainedValidationErrorTemplateData{
 		Code:      status,
-		Message:   err.Error(),
+		Message:   errs.Error(),
+		Errors:    messages,
 		RequestID: r.requestID,
 	}
 }
 
-func errorToConstrainedTemplate(r *Request, status int) constrainedErrorTemplate {
-	return constrainedErrorTemplate{
+func errorToConstrainedTemplate(r *Request, status int, message string) ConstrainedErrorTemplateData {
+	return ConstrainedErrorTemplateData{
 		Code:       status,
 		StatusName: http.StatusText(status),
+		Message:    message,
 		RequestID:  r.requestID,
 	}
 }
 
-func errorToTemplate(r *Request, err error, status int) errorTemplate {
+func errorToTemplate(r *Request, err error, status int) ErrorTemplateData {
 	environment := map[string]string{}
 	for _, e := range os.Environ() {
 		comps := strings.SplitN(e, "=", 2)
 		environment[comps[0]] = comps[1]
 	}
 
-	tmpl := errorTemplate{
+	tmpl := ErrorTemplateData{
 		Code:         status,
 		StatusName:   http.StatusText(status),
 		Method:       r.HTTPRequest.Method,
@@ -241,10 +329,11 @@ func errorToTemplate(r *Request, err error, status int) errorTemplate {
 		ErrorType:    "",
 		ErrorPackage: "",
 		StackTrace:   "«Stack Trace not Available»",
-		Headers:      oneToManyMap(r.HTTPRequest.Header),
-		RequestDetails: requestInfo{
-			Queries: oneToManyMap(r.HTTPRequest.URL.Query()),
-			Form:    oneToManyMap(r.HTTPRequest.PostForm),
+		Headers:      OneToManyMap(r.HTTPRequest.Header),
+		RequestDetails: RequestInfo{
+			Queries: OneToManyMap(r.HTTPRequest.URL.Query()),
+			Form:    OneToManyMap(r.HTTPRequest.PostForm),
+			Cookies: requestCookies(r.HTTPRequest),
 		},
 		Environment: environment,
 	}
@@ -275,22 +364,22 @@ func errorToTemplate(r *Request, err error, status int) errorTemplate {
 
 func renderErrorTemplate(r *Request, err error, status int, name string) (string, error) {
 	tmpl := errorToTemplate(r, err, status)
-	return templates.TemplateNamed(name)(tmpl)
+	return r.mux.executeErrorTemplate(name, tmpl)
 }
 
-func renderValidationErrorTemplate(r *Request, err ValidationError, status int, name string) (string, error) {
-	tmpl := validationErrorToTemplate(r, err, status)
-	return templates.TemplateNamed(name)(tmpl)
+func renderValidationErrorTemplate(r *Request, errs ValidationErrors, status int, name string) (string, error) {
+	tmpl := validationErrorToTemplate(r, errs, status)
+	return r.mux.executeErrorTemplate(name, tmpl)
 }
 
-func renderConstrainedErrorTemplate(r *Request, status int, name string) (string, error) {
-	tmpl := errorToConstrainedTemplate(r, status)
-	return templates.TemplateNamed(name)(tmpl)
+func renderConstrainedErrorTemplate(r *Request, status int, message, name string) (string, error) {
+	tmpl := errorToConstrainedTemplate(r, status, message)
+	return r.mux.executeErrorTemplate(name, tmpl)
 }
 
-func renderConstrainedValidationTemplate(r *Request, err ValidationError, status int, name string) (string, error) {
-	tmpl := validationErrorToConstrainedTemplate(r, err, status)
-	return templates.TemplateNamed(name)(tmpl)
+func renderConstrainedValidationTemplate(r *Request, errs ValidationErrors, status int, name string) (string, error) {
+	tmpl := validationErrorToConstrainedTemplate(r, errs, status)
+	return r.mux.executeErrorTemplate(name, tmpl)
 }
 
 func renderHTMLErrorTemplate(r *Request, err error, status int) (string, error) {
@@ -301,39 +390,47 @@ func renderTextErrorTemplate(r *Request, err error, status int) (string, error)
 	return renderErrorTemplate(r, err, status, templates.ServerErrorText)
 }
 
-func renderHTMLValidationErrorTemplate(r *Request, err ValidationError, status int) (string, error) {
-	return renderValidationErrorTemplate(r, err, status, templates.ValidationErrorHTML)
+func renderHTMLValidationErrorTemplate(r *Request, errs ValidationErrors, status int) (string, error) {
+	return renderValidationErrorTemplate(r, errs, status, templates.ValidationErrorHTML)
 }
 
-func renderTextValidationErrorTemplate(r *Request, err ValidationError, status int) (string, error) {
-	return renderValidationErrorTemplate(r, err, status, templates.ValidationErrorText)
+func renderTextValidationErrorTemplate(r *Request, errs ValidationErrors, status int) (string, error) {
+	return renderValidationErrorTemplate(r, errs, status, templates.ValidationErrorText)
 }
 
-func renderConstrainedTextValidationErrorTemplate(r *Request, err ValidationError, status int) (string, error) {
-	return renderConstrainedValidationTemplate(r, err, status, templates.ValidationErrorConstrainedText)
+func renderConstrainedTextValidationErrorTemplate(r *Request, errs ValidationErrors, status int) (string, error) {
+	return renderConstrainedValidationTemplate(r, errs, status, templates.ValidationErrorConstrainedText)
 }
 
-func renderConstrainedHTMLValidationErrorTemplate(r *Request, err ValidationError, status int) (string, error) {
-	return renderConstrainedValidationTemplate(r, err, status, templates.ValidationErrorConstrainedHTML)
+func renderConstrainedHTMLValidationErrorTemplate(r *Request, errs ValidationErrors, status int) (string, error) {
+	return renderConstrainedValidationTemplate(r, errs, status, templates.ValidationErrorConstrainedHTML)
 }
 
-func renderConstrainedTextErrorTemplate(r *Request, status int) (string, error) {
-	return renderConstrainedErrorTemplate(r, status, templates.ServerErrorConstrainedText)
+func renderConstrainedTextErrorTemplate(r *Request, status int, message string) (string, error) {
+	return renderConstrainedErrorTemplate(r, status, message, templates.ServerErrorConstrainedText)
 }
 
-func renderConstrainedHTMLErrorTemplate(r *Request, status int) (string, error) {
-	return renderConstrainedErrorTemplate(r, status, templates.ServerErrorConstrainedHTML)
+func renderConstrainedHTMLErrorTemplate(r *Request, status int, message string) (string, error) {
+	return renderConstrainedErrorTemplate(r, status, message, templates.ServerErrorConstrainedHTML)
 }
 
-func listRoutes(mx *Mux, routes []chi.Route) []routeInfo {
-	var result []routeInfo
+func listRoutes(mx *Mux, prefix string, routes []chi.Route) []RouteInfo {
+	var result []RouteInfo
 	for _, v := range routes {
+		if v.SubRoutes != nil {
+			// Handlers for mounted routes are stubs created by chi. List the
+			// sub-router routes instead.
+			subPrefix := prefix + strings.TrimSuffix(v.Pattern, "/*")
+			result = append(result, listRoutes(mx, subPrefix, v.SubRoutes.Routes())...)
+			continue
+		}
+		pattern := prefix + v.Pattern
 		for method := range v.Handlers {
-			result = append(result, routeInfo{
+			result = append(result, RouteInfo{
 				Method:  method,
-				Pattern: v.Pattern,
+				Pattern: pattern,
 				Handler: func() string {
-					h := mx.handlerMetaFor(method, v.Pattern)
+					h := mx.handlerMetaFor(method, pattern)
 					if h == nil {
 						return "«Unknown»"
 					}
@@ -342,33 +439,31 @@ func listRoutes(mx *Mux, routes []chi.Route) []routeInfo {
 				}(),
 			})
 		}
-		if v.SubRoutes != nil {
-			result = append(result, listRoutes(mx, v.SubRoutes.Routes())...)
-		}
 	}
 
 	return result
 }
 
-func notFoundToTemplate(status int, r *Request) notFoundTemplate {
+func notFoundToTemplate(status int, r *Request) NotFoundTemplateData {
 	environment := map[string]string{}
 	for _, e := range os.Environ() {
 		comps := strings.SplitN(e, "=", 2)
 		environment[comps[0]] = comps[1]
 	}
 
-	tmpl := notFoundTemplate{
+	tmpl := NotFoundTemplateData{
 		Code:       status,
 		StatusName: http.StatusText(status),
 		Method:     r.HTTPRequest.Method,
 		Path:       r.HTTPRequest.URL.Path,
-		Headers:    oneToManyMap(r.HTTPRequest.Header),
-		RequestDetails: requestInfo{
-			Queries: oneToManyMap(r.HTTPRequest.URL.Query()),
-			Form:    oneToManyMap(r.HTTPRequest.PostForm),
+		Headers:    OneToManyMap(r.HTTPRequest.Header),
+		RequestDetails: RequestInfo{
+			Queries: OneToManyMap(r.HTTPRequest.URL.Query()),
+			Form:    OneToManyMap(r.HTTPRequest.PostForm),
+			Cookies: requestCookies(r.HTTPRequest),
 		},
 		Environment: environment,
-		Routes:      listRoutes(r.mux, r.mux.internalMux.Routes()),
+		Routes:      listRoutes(r.mux, r.mux.routePrefix, r.mux.internalMux.Routes()),
 	}
 
 	return tmpl
@@ -376,25 +471,47 @@ func notFoundToTemplate(status int, r *Request) notFoundTemplate {
 
 func renderNotFoundHTMLErrorTemplate(r *Request) (string, error) {
 	tmpl := notFoundToTemplate(http.StatusNotFound, r)
-	return templates.TemplateNamed(templates.NotFoundErrorHTML)(tmpl)
+	return r.mux.executeErrorTemplate(templates.NotFoundErrorHTML, tmpl)
 }
 
 func renderNotFoundTextErrorTemplate(r *Request) (string, error) {
 	tmpl := notFoundToTemplate(http.StatusNotFound, r)
-	return templates.TemplateNamed(templates.NotFoundErrorText)(tmpl)
+	return r.mux.executeErrorTemplate(templates.NotFoundErrorText, tmpl)
 }
 
 func renderMethodNotAllowedHTMLErrorTemplate(r *Request) (string, error) {
 	tmpl := notFoundToTemplate(http.StatusMethodNotAllowed, r)
-	return templates.TemplateNamed(templates.NotFoundErrorHTML)(tmpl)
+	return r.mux.executeErrorTemplate(templates.NotFoundErrorHTML, tmpl)
 }
 
 func renderMethodNotAllowedTextErrorTemplate(r *Request) (string, error) {
 	tmpl := notFoundToTemplate(http.StatusMethodNotAllowed, r)
-	return templates.TemplateNamed(templates.NotFoundErrorText)(tmpl)
+	return r.mux.executeErrorTemplate(templates.NotFoundErrorText, tmpl)
 }
 
-var notFoundConstrainedHTMLErrorTemplate, _ = templates.TemplateNamed(templates.NotFoundErrorConstrainedHTML)(notFoundConstrainedValue)
-var notFoundConstrainedTextErrorTemplate, _ = templates.TemplateNamed(templates.NotFoundErrorConstrainedText)(notFoundConstrainedValue)
-var methodNotAllowedConstrainedHTMLErrorTemplate, _ = templates.TemplateNamed(templates.NotFoundErrorConstrainedHTML)(methodNotAllowedConstrainedValue)
-var methodNotAllowedConstrainedTextErrorTemplate, _ = templates.TemplateNamed(templates.NotFoundErrorConstrainedText)(methodNotAllowedConstrainedValue)
+// NotAcceptableTemplateData is passed to the NotAcceptableErrorHTML and
+// NotAcceptableErrorText templates, used to render content negotiation
+// errors.
+type NotAcceptableTemplateData struct {
+	XMLName    xml.Name `json:"-" xml:"not_acceptable"`
+	Code       int      `json:"code,omitempty" xml:"code"`
+	StatusName string   `json:"status_name,omitempty" xml:"status_name"`
+	Message    string   `json:"message,omitempty" xml:"message"`
+	Available  []string `json:"available,omitempty" xml:"available>media_type"`
+	RequestID  string   `json:"request_id,omitempty" xml:"request_id"`
+}
+
+func negotiationErrorToTemplate(r *Request, err *negotiationError) NotAcceptableTemplateData {
+	return NotAcceptableTemplateData{
+		Code:       err.status(),
+		StatusName: http.StatusText(err.status()),
+		Message:    err.message(),
+		Available:  err.available(),
+		RequestID:  r.requestID,
+	}
+}
+
+func renderNegotiationErrorTemplate(r *Request, err *negotiationError, name string) (string, error) {
+	tmpl := negotiationErrorToTemplate(r, err)
+	return r.mux.executeErrorTemplate(name, tmpl)
+}
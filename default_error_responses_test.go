@@ -409,3 +409,74 @@ func TestMethodNotAllowedErrorConstrained(t *testing.T) {
 		assert.NotContains(t, body, `"environment"`)
 	})
 }
+
+func TestValidationErrorsCollected(t *testing.T) {
+	t.Parallel()
+	type RequestType struct {
+		*Request
+		First  string `header:"first" required:"true"`
+		Second string `header:"second" required:"true"`
+	}
+
+	for _, development := range []bool{true, false} {
+		m := NewMux(zap.NewNop())
+		m.Development = development
+		m.CollectValidationErrors = true
+		m.Post("/", func(r *RequestType) error {
+			return nil
+		})
+
+		for _, accept := range []string{"text/plain", "text/html", "text/xml", "application/json"} {
+			code, body := doRequest(m, accept, "POST", "/", nil)
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Contains(t, body, "Value for field first is required", accept)
+			assert.Contains(t, body, "Value for field second is required", accept)
+		}
+	}
+}
+
+func TestValidationErrorConstraintDevelopment(t *testing.T) {
+	m := NewMux(zap.NewNop())
+	m.Development = true
+	m.Get("/", func(r *struct {
+		*Request
+		Page int `query:"page" max:"10"`
+	}) error {
+		return nil
+	})
+
+	code, body := doRequest(m, "text/plain", "GET", "/?page=11", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, "Constraint: max=10")
+
+	code, body = doRequest(m, "application/json", "GET", "/?page=11", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body, `"constraint":"max=10"`)
+}
+
+func TestValidationErrorCookieDevelopment(t *testing.T) {
+	m := NewMux(zap.NewNop())
+	m.Development = true
+	m.Get("/", func(r *struct {
+		*Request
+		Session string `cookie:"session_id" pattern:"^[a-f0-9]+$"`
+	}) error {
+		return nil
+	})
+
+	for accept, expected := range map[string][]string{
+		"text/plain":       {"session_id: xyz", "Field Source: Cookie"},
+		"text/html":        {"<td><code>session_id</code></td> <td><code>xyz</code></td>"},
+		"application/json": {`"cookies":{"session_id":["xyz"]}`, `"field_source":"Cookie"`},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", accept)
+		r.AddCookie(&http.Cookie{Name: "session_id", Value: "xyz"})
+		rw := httptest.NewRecorder()
+		m.ServeHTTP(rw, r)
+		assert.Equal(t, http.StatusBadRequest, rw.Code)
+		for _, e := range expected {
+			assert.Contains(t, rw.Body.String(), e, accept)
+		}
+	}
+}
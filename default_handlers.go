@@ -1,25 +1,49 @@
 package raggett
 
 import (
+	"errors"
 	"net/http"
 
 	"go.uber.org/zap"
+
+	"github.com/heyvito/raggett/templates"
 )
 
-func (mx *Mux) defaultValidationErrorHandler(err ValidationError, w http.ResponseWriter, r *Request) {
+func defaultValidationErrorHandler(err ValidationError, w http.ResponseWriter, r *Request) {
 	r.Logger.Error("Validation error serving request", zap.Error(err))
+	respondValidationErrors(ValidationErrors{err}, r)
+}
+
+func defaultValidationErrorsHandler(errs ValidationErrors, w http.ResponseWriter, r *Request) {
+	r.Logger.Error("Validation errors serving request", zap.Error(errs))
+	respondValidationErrors(errs, r)
+}
 
+func respondValidationErrors(errs ValidationErrors, r *Request) {
 	if r.flushedHeaders {
 		// Do not attempt to change the request in case we have already flushed
 		// headers.
 		return
 	}
 
+	status := http.StatusBadRequest
+	for _, e := range errs {
+		if e.StatusCode != 0 {
+			status = e.StatusCode
+			break
+		}
+	}
+
+	if r.mux.ProblemDetails {
+		writeProblem(r, validationProblem(errs, status))
+		return
+	}
+
 	vErr := validationErrorResponse{
-		err:         err,
+		errs:        errs,
 		r:           r,
-		status:      http.StatusBadRequest,
-		constrained: !mx.Development,
+		status:      status,
+		constrained: !r.mux.Development,
 	}
 	r.SetStatus(vErr.status)
 
@@ -28,8 +52,17 @@ func (mx *Mux) defaultValidationErrorHandler(err ValidationError, w http.Respons
 	}
 }
 
-func (mx *Mux) defaultRuntimeErrorHandler(err error, w http.ResponseWriter, r *Request) {
-	r.Logger.Error("Runtime error serving request", zap.Error(err))
+func defaultRuntimeErrorHandler(err error, w http.ResponseWriter, r *Request) {
+	if r.isAbortNotFound(err) {
+		r.Logger.Info("Request aborted with NotFound")
+		if !r.flushedHeaders {
+			r.mux.internalNotFoundDispatch(w, r.HTTPRequest)
+		}
+		return
+	}
+
+	status := r.mux.statusForError(err)
+	logRuntimeError(r, status, err)
 
 	if r.flushedHeaders {
 		// Do not attempt to change the request in case we have already flushed
@@ -37,13 +70,52 @@ func (mx *Mux) defaultRuntimeErrorHandler(err error, w http.ResponseWriter, r *R
 		return
 	}
 
-	r.SetStatus(http.StatusInternalServerError)
+	if p, ok := asProblem(err); ok {
+		writeProblem(r, p)
+		return
+	}
+
+	message := ""
+	if httpErr, ok := r.mux.httpErrorFor(err); ok {
+		for name, values := range httpErr.Headers {
+			for _, v := range values {
+				r.AddHeader(name, v)
+			}
+		}
+		message = httpErr.message()
+	}
+
+	var negErr *negotiationError
+	isNegotiationErr := errors.As(err, &negErr)
+
+	if r.mux.ProblemDetails {
+		p := NewProblem(status, message)
+		if r.mux.Development {
+			p.Detail = err.Error()
+		}
+		if isNegotiationErr {
+			p.With("available", negErr.available())
+		}
+		writeProblem(r, p)
+		return
+	}
+
+	r.SetStatus(status)
+	if !r.bodyAllowedForStatus() {
+		return
+	}
+
+	if isNegotiationErr {
+		writeResponder(r, negotiationErrorResponse{r: r, err: negErr})
+		return
+	}
 
 	writeResponder(r, errorResponse{
 		err:         err,
 		r:           r,
-		status:      http.StatusInternalServerError,
-		constrained: !mx.Development,
+		status:      status,
+		message:     message,
+		constrained: !r.mux.Development,
 	})
 }
 
@@ -52,18 +124,26 @@ func (mx *Mux) defaultNotFoundHandler(w http.ResponseWriter, r *http.Request) {
 	// Let's create a minimal one and try to move along. The same happens to
 	// defaultMethodNotAllowedHandler.
 	req := newRequest(mx, w, r)
+	if req.mux.ProblemDetails {
+		writeProblem(req, NewProblem(http.StatusNotFound, notFoundConstrainedValue.Message))
+		return
+	}
 	req.SetStatus(http.StatusNotFound)
 	writeResponder(req, notFoundErrorResponse{
-		constrained: !mx.Development,
+		constrained: !req.mux.Development,
 		r:           req,
 	})
 }
 
 func (mx *Mux) defaultMethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
 	req := newRequest(mx, w, r)
+	if req.mux.ProblemDetails {
+		writeProblem(req, NewProblem(http.StatusMethodNotAllowed, methodNotAllowedConstrainedValue.Message))
+		return
+	}
 	req.SetStatus(http.StatusMethodNotAllowed)
 	writeResponder(req, methodNotAllowedErrorResponse{
-		constrained: !mx.Development,
+		constrained: !req.mux.Development,
 		r:           req,
 	})
 }
@@ -71,7 +151,7 @@ func (mx *Mux) defaultMethodNotAllowedHandler(w http.ResponseWriter, r *http.Req
 // Mark: - validationErrorResponse
 
 type validationErrorResponse struct {
-	err         ValidationError
+	errs        ValidationErrors
 	r           *Request
 	status      int
 	constrained bool
@@ -79,10 +159,10 @@ type validationErrorResponse struct {
 
 func (v validationErrorResponse) JSON() interface{} {
 	if v.constrained {
-		return validationErrorToConstrainedTemplate(v.r, v.err, v.status)
+		return validationErrorToConstrainedTemplate(v.r, v.errs, v.status)
 	}
 
-	return validationErrorToTemplate(v.r, v.err, v.status)
+	return validationErrorToTemplate(v.r, v.errs, v.status)
 }
 
 func (v validationErrorResponse) XML() interface{} {
@@ -95,9 +175,9 @@ func (v validationErrorResponse) HTML() string {
 		err error
 	)
 	if v.constrained {
-		r, err = renderConstrainedHTMLValidationErrorTemplate(v.r, v.err, v.status)
+		r, err = renderConstrainedHTMLValidationErrorTemplate(v.r, v.errs, v.status)
 	} else {
-		r, err = renderHTMLValidationErrorTemplate(v.r, v.err, v.status)
+		r, err = renderHTMLValidationErrorTemplate(v.r, v.errs, v.status)
 	}
 
 	if err != nil {
@@ -112,9 +192,9 @@ func (v validationErrorResponse) PlainText() string {
 		err error
 	)
 	if v.constrained {
-		r, err = renderConstrainedTextValidationErrorTemplate(v.r, v.err, v.status)
+		r, err = renderConstrainedTextValidationErrorTemplate(v.r, v.errs, v.status)
 	} else {
-		r, err = renderTextValidationErrorTemplate(v.r, v.err, v.status)
+		r, err = renderTextValidationErrorTemplate(v.r, v.errs, v.status)
 	}
 	if err != nil {
 		panic("raggett: Failed rendering validation error template: " + err.Error())
@@ -128,12 +208,13 @@ type errorResponse struct {
 	err         error
 	r           *Request
 	status      int
+	message     string
 	constrained bool
 }
 
 func (e errorResponse) JSON() interface{} {
 	if e.constrained {
-		return errorToConstrainedTemplate(e.r, e.status)
+		return errorToConstrainedTemplate(e.r, e.status, e.message)
 	}
 	return errorToTemplate(e.r, e.err, e.status)
 }
@@ -148,7 +229,7 @@ func (e errorResponse) HTML() string {
 		err error
 	)
 	if e.constrained {
-		r, err = renderConstrainedHTMLErrorTemplate(e.r, e.status)
+		r, err = renderConstrainedHTMLErrorTemplate(e.r, e.status, e.message)
 	} else {
 		r, err = renderHTMLErrorTemplate(e.r, e.err, e.status)
 	}
@@ -164,7 +245,7 @@ func (e errorResponse) PlainText() string {
 		err error
 	)
 	if e.constrained {
-		r, err = renderConstrainedTextErrorTemplate(e.r, e.status)
+		r, err = renderConstrainedTextErrorTemplate(e.r, e.status, e.message)
 	} else {
 		r, err = renderTextErrorTemplate(e.r, e.err, e.status)
 	}
@@ -198,7 +279,7 @@ func (nf notFoundErrorResponse) HTML() string {
 		err error
 	)
 	if nf.constrained {
-		r = notFoundConstrainedHTMLErrorTemplate
+		r, err = nf.r.mux.executeErrorTemplate(templates.NotFoundErrorConstrainedHTML, notFoundConstrainedValue)
 	} else {
 		r, err = renderNotFoundHTMLErrorTemplate(nf.r)
 	}
@@ -214,7 +295,7 @@ func (nf notFoundErrorResponse) PlainText() string {
 		err error
 	)
 	if nf.constrained {
-		r = notFoundConstrainedTextErrorTemplate
+		r, err = nf.r.mux.executeErrorTemplate(templates.NotFoundErrorConstrainedText, notFoundConstrainedValue)
 	} else {
 		r, err = renderNotFoundTextErrorTemplate(nf.r)
 	}
@@ -248,7 +329,7 @@ func (mn methodNotAllowedErrorResponse) HTML() string {
 		err error
 	)
 	if mn.constrained {
-		r = methodNotAllowedConstrainedHTMLErrorTemplate
+		r, err = mn.r.mux.executeErrorTemplate(templates.NotFoundErrorConstrainedHTML, methodNotAllowedConstrainedValue)
 	} else {
 		r, err = renderMethodNotAllowedHTMLErrorTemplate(mn.r)
 	}
@@ -264,7 +345,7 @@ func (mn methodNotAllowedErrorResponse) PlainText() string {
 		err error
 	)
 	if mn.constrained {
-		r = methodNotAllowedConstrainedTextErrorTemplate
+		r, err = mn.r.mux.executeErrorTemplate(templates.NotFoundErrorConstrainedText, methodNotAllowedConstrainedValue)
 	} else {
 		r, err = renderMethodNotAllowedTextErrorTemplate(mn.r)
 	}
@@ -273,3 +354,34 @@ func (mn methodNotAllowedErrorResponse) PlainText() string {
 	}
 	return r
 }
+
+// Mark: - negotiationErrorResponse
+
+type negotiationErrorResponse struct {
+	r   *Request
+	err *negotiationError
+}
+
+func (ne negotiationErrorResponse) JSON() interface{} {
+	return negotiationErrorToTemplate(ne.r, ne.err)
+}
+
+func (ne negotiationErrorResponse) XML() interface{} {
+	return ne.JSON()
+}
+
+func (ne negotiationErrorResponse) HTML() string {
+	r, err := renderNegotiationErrorTemplate(ne.r, ne.err, templates.NotAcceptableErrorHTML)
+	if err != nil {
+		panic("raggett: Failed rendering NotAcceptable error template: " + err.Error())
+	}
+	return r
+}
+
+func (ne negotiationErrorResponse) PlainText() string {
+	r, err := renderNegotiationErrorTemplate(ne.r, ne.err, templates.NotAcceptableErrorText)
+	if err != nil {
+		panic("raggett: Failed rendering NotAcceptable error template: " + err.Error())
+	}
+	return r
+}
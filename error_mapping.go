@@ -0,0 +1,110 @@
+package raggett
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorMappingOption configures an error mapping registered through
+// Mux.MapError or MapErrorType.
+type ErrorMappingOption func(*errorMapping)
+
+// WithErrorMessage sets the message sent to clients when a mapped error is
+// returned. Defaults to the text of the mapped status.
+func WithErrorMessage(message string) ErrorMappingOption {
+	return func(m *errorMapping) {
+		m.message = message
+	}
+}
+
+// WithErrorHeader adds a header to be sent along with responses to mapped
+// errors.
+func WithErrorHeader(name, value string) ErrorMappingOption {
+	return func(m *errorMapping) {
+		if m.headers == nil {
+			m.headers = http.Header{}
+		}
+		m.headers.Add(name, value)
+	}
+}
+
+type errorMapping struct {
+	matches func(err error) bool
+	status  int
+	message string
+	headers http.Header
+}
+
+// MapError registers the status used by the default error handler to respond
+// to handlers returning errors matching target, as determined by errors.Is.
+// Mappings are consulted in registration order, and only when the returned
+// error does not wrap an HTTPError or a Problem. Mappings also apply to
+// sub-routers created through Route, Group and With, even when registered
+// after the sub-router; mappings registered on a sub-router take precedence
+// over its parent's, and do not affect the parent.
+//
+//	mux.MapError(domain.ErrNotFound, http.StatusNotFound)
+//	mux.MapError(domain.ErrPermissionDenied, http.StatusForbidden,
+//		raggett.WithErrorMessage("You are not allowed to do that."))
+func (mx *Mux) MapError(target error, status int, opts ...ErrorMappingOption) {
+	mx.addErrorMapping(func(err error) bool { return errors.Is(err, target) }, status, opts)
+}
+
+// MapErrorType registers the status used by the default error handler to
+// respond to handlers returning errors of type T, as determined by errors.As.
+// See Mux.MapError.
+//
+//	raggett.MapErrorType[*domain.ValidationError](mux, http.StatusUnprocessableEntity)
+func MapErrorType[T error](mx *Mux, status int, opts ...ErrorMappingOption) {
+	mx.addErrorMapping(func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}, status, opts)
+}
+
+func (mx *Mux) addErrorMapping(matches func(error) bool, status int, opts []ErrorMappingOption) {
+	m := errorMapping{matches: matches, status: status}
+	for _, o := range opts {
+		o(&m)
+	}
+	mx.errorMappings = append(mx.errorMappings, m)
+}
+
+// mappedError returns an HTTPError describing a given error, in case it
+// matches a mapping registered on the Mux or any of its parents.
+func (mx *Mux) mappedError(err error) (*HTTPError, bool) {
+	for m := mx; m != nil; m = m.parent {
+		for _, mapping := range m.errorMappings {
+			if mapping.matches(err) {
+				return &HTTPError{
+					Status:  mapping.status,
+					Message: mapping.message,
+					Cause:   err,
+					Headers: mapping.headers,
+				}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// httpErrorFor returns the HTTPError describing a given error, either wrapped
+// by it or produced by a mapping registered on the Mux.
+func (mx *Mux) httpErrorFor(err error) (*HTTPError, bool) {
+	if httpErr, ok := asHTTPError(err); ok {
+		return httpErr, true
+	}
+	return mx.mappedError(err)
+}
+
+// statusForError returns the status used by the default error handler to
+// respond to a given error.
+func (mx *Mux) statusForError(err error) int {
+	if p, ok := asProblem(err); ok {
+		return p.status()
+	}
+	if httpErr, ok := mx.httpErrorFor(err); ok {
+		return httpErr.status()
+	}
+	return http.StatusInternalServerError
+}
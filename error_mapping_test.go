@@ -0,0 +1,134 @@
+package raggett
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var (
+	errTestRecordNotFound = errors.New("record not found")
+	errTestDenied         = errors.New("permission denied")
+)
+
+type testQuotaError struct {
+	limit int
+}
+
+func (e *testQuotaError) Error() string {
+	return fmt.Sprintf("quota of %d exceeded", e.limit)
+}
+
+func TestMapError(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Development = false
+	mx.MapError(errTestRecordNotFound, http.StatusNotFound)
+	mx.MapError(errTestDenied, http.StatusForbidden,
+		WithErrorMessage("You are not allowed to do that."),
+		WithErrorHeader("X-Reason", "permissions"))
+	MapErrorType[*testQuotaError](mx, http.StatusTooManyRequests)
+
+	mx.Get("/missing", func(r *struct{ *Request }) error {
+		return fmt.Errorf("loading user: %w", errTestRecordNotFound)
+	})
+	mx.Get("/denied", func(r *struct{ *Request }) error {
+		return errTestDenied
+	})
+	mx.Get("/quota", func(r *struct{ *Request }) error {
+		return fmt.Errorf("uploading: %w", &testQuotaError{limit: 10})
+	})
+	mx.Get("/panic", func(r *struct{ *Request }) error {
+		r.AbortError(makeError(errTestRecordNotFound))
+		return nil
+	})
+	mx.Get("/explicit", func(r *struct{ *Request }) error {
+		return Gone("").Wrap(errTestRecordNotFound)
+	})
+	mx.Get("/unmapped", func(r *struct{ *Request }) error {
+		return fmt.Errorf("boom")
+	})
+
+	t.Run("Is", func(t *testing.T) {
+		code, body := doRequest(mx, "text/plain", "GET", "/missing", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Contains(t, body, "Not Found")
+		assert.NotContains(t, body, "loading user")
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/denied", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "permissions", w.Header().Get("X-Reason"))
+		assert.Contains(t, w.Body.String(), `"message":"You are not allowed to do that."`)
+	})
+
+	t.Run("As", func(t *testing.T) {
+		code, _ := doRequest(mx, "text/plain", "GET", "/quota", nil)
+		assert.Equal(t, http.StatusTooManyRequests, code)
+	})
+
+	t.Run("raggett.Error", func(t *testing.T) {
+		code, _ := doRequest(mx, "text/plain", "GET", "/panic", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("HTTPError Precedence", func(t *testing.T) {
+		code, _ := doRequest(mx, "text/plain", "GET", "/explicit", nil)
+		assert.Equal(t, http.StatusGone, code)
+	})
+
+	t.Run("Unmapped", func(t *testing.T) {
+		code, _ := doRequest(mx, "text/plain", "GET", "/unmapped", nil)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+}
+
+func TestMapErrorSubRouter(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.MapError(errTestRecordNotFound, http.StatusNotFound)
+	mx.Route("/admin", func(r *Mux) {
+		r.MapError(errTestDenied, http.StatusForbidden)
+		r.Get("/", func(r *struct{ *Request }) error {
+			return errTestRecordNotFound
+		})
+	})
+	mx.Get("/", func(r *struct{ *Request }) error {
+		return errTestDenied
+	})
+
+	code, _ := doRequest(mx, "text/plain", "GET", "/admin/", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = doRequest(mx, "text/plain", "GET", "/", nil)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestMapErrorAfterRoutes(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Route("/admin", func(r *Mux) {
+		r.Get("/", func(r *struct{ *Request }) error {
+			return errTestRecordNotFound
+		})
+	})
+	mx.Group(func(r *Mux) {
+		r.Get("/group", func(r *struct{ *Request }) error {
+			return errTestDenied
+		})
+	})
+	mx.MapError(errTestRecordNotFound, http.StatusNotFound)
+	mx.MapError(errTestDenied, http.StatusForbidden)
+
+	code, _ := doRequest(mx, "text/plain", "GET", "/admin/", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = doRequest(mx, "text/plain", "GET", "/group", nil)
+	assert.Equal(t, http.StatusForbidden, code)
+}
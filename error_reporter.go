@@ -0,0 +1,261 @@
+package raggett
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// defaultErrorReportQueueSize is the queue size used by SetErrorReporter when
+// a non-positive size is provided.
+const defaultErrorReportQueueSize = 64
+
+// scrubbedValue replaces values of sensitive headers and query string
+// parameters in ErrorReport values.
+const scrubbedValue = "[FILTERED]"
+
+// ErrorReport describes a runtime error or recovered panic that caused a
+// request to fail with a 5xx status.
+type ErrorReport struct {
+	// Error contains the reported error along with a stack trace. For panics,
+	// the trace leads to the point the panic was raised. For returned errors
+	// wrapping an Error, it is the trace held by that Error. Other errors do
+	// not carry a trace, so it leads to the point Raggett received the error
+	// instead, starting at the handler's responder rather than where the
+	// error was created.
+	Error Error
+	// Status is the HTTP status of the response.
+	Status int
+	// RequestID is the identifier of the failed request.
+	RequestID string
+	// Pattern is the route pattern matched by the request (e.g. "/users/{id}").
+	Pattern string
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the path requested by the client.
+	Path string
+	// Headers contains headers sent by the client. Values of headers whose
+	// names suggest they contain credentials, such as Authorization and
+	// Cookie, are replaced by "[FILTERED]".
+	Headers http.Header
+	// Query contains the request's query string values, scrubbed like Headers.
+	Query url.Values
+	// Time is the moment the error was reported.
+	Time time.Time
+}
+
+// ErrorReporter receives reports of runtime errors and recovered panics,
+// usually forwarding them to an error aggregation service. ReportError is
+// invoked from a single goroutine, outside of the request's lifecycle.
+type ErrorReporter interface {
+	ReportError(report ErrorReport)
+}
+
+// errorReportQueue delivers reports to an ErrorReporter from a separate
+// goroutine. Reports are dropped when the queue is full.
+type errorReportQueue struct {
+	mu       sync.RWMutex
+	closed   bool
+	reports  chan ErrorReport
+	done     chan struct{}
+	reporter ErrorReporter
+	logger   *zap.Logger
+}
+
+func newErrorReportQueue(reporter ErrorReporter, size int, logger *zap.Logger) *errorReportQueue {
+	if size <= 0 {
+		size = defaultErrorReportQueueSize
+	}
+	q := &errorReportQueue{
+		reports:  make(chan ErrorReport, size),
+		done:     make(chan struct{}),
+		reporter: reporter,
+		logger:   logger,
+	}
+	go q.run()
+	return q
+}
+
+func (q *errorReportQueue) run() {
+	defer close(q.done)
+	for report := range q.reports {
+		q.deliver(report)
+	}
+}
+
+func (q *errorReportQueue) deliver(report ErrorReport) {
+	defer func() {
+		if err := recover(); err != nil {
+			q.logger.Error("ErrorReporter panicked", zap.String("error", fmt.Sprintf("%v", err)))
+		}
+	}()
+	q.reporter.ReportError(report)
+}
+
+func (q *errorReportQueue) enqueue(report ErrorReport) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return
+	}
+	select {
+	case q.reports <- report:
+	default:
+		q.logger.Warn("Error report queue is full. Dropping report.",
+			zap.String("request_id", report.RequestID))
+	}
+}
+
+func (q *errorReportQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.reports)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetErrorReporter registers a reporter to be notified about runtime errors
+// and recovered panics causing requests to fail with a 5xx status, regardless
+// of the handler set by HandleError. Reports are delivered asynchronously
+// through a queue holding up to queueSize reports (64, when queueSize is not
+// positive); reports are dropped while the queue is full. Sub-routers created
+// through Route, Group and With use the reporter of the closest Mux setting
+// one, so a sub-router may set its own reporter without affecting its parent.
+// Passing a nil reporter removes the reporter set on this Mux. Use
+// CloseErrorReporter to deliver pending reports before the application exits.
+func (mx *Mux) SetErrorReporter(reporter ErrorReporter, queueSize int) {
+	if mx.errorReports != nil {
+		go func(q *errorReportQueue) { _ = q.close(context.Background()) }(mx.errorReports)
+	}
+	mx.errorReports = nil
+	if reporter != nil {
+		mx.errorReports = newErrorReportQueue(reporter, queueSize, mx.logger)
+	}
+}
+
+// CloseErrorReporter stops accepting reports, and waits until pending reports
+// are delivered to the reporter set on this Mux by SetErrorReporter, or ctx is
+// done.
+func (mx *Mux) CloseErrorReporter(ctx context.Context) error {
+	if mx.errorReports == nil {
+		return nil
+	}
+	return mx.errorReports.close(ctx)
+}
+
+// reportError enqueues a report for a given error, in case it causes a 5xx
+// response and a reporter is registered. stack is the stack trace of the
+// panic that caused the error, if any. Otherwise, the trace of the caller is
+// used, as described by ErrorReport.Error.
+func (mx *Mux) reportError(r *Request, err error, stack []StackFrame) {
+	queue := mx.errorReportQueue()
+	if queue == nil || err == errAbortRequest || r.isAbortNotFound(err) {
+		return
+	}
+	status := mx.statusForError(err)
+	if status < 500 {
+		return
+	}
+
+	var ragErr Error
+	if !errors.As(err, &ragErr) {
+		if stack == nil {
+			stack = getStack(2)
+		}
+		ragErr = Error{StackTrace: stack, OriginalError: err}
+	}
+
+	httpReq := r.HTTPRequest
+	pattern := ""
+	if rctx := chi.RouteContext(httpReq.Context()); rctx != nil {
+		pattern = rctx.RoutePattern()
+	}
+
+	queue.enqueue(ErrorReport{
+		Error:     ragErr,
+		Status:    status,
+		RequestID: r.requestID,
+		Pattern:   pattern,
+		Method:    httpReq.Method,
+		Path:      httpReq.URL.Path,
+		Headers:   scrubValues(httpReq.Header),
+		Query:     scrubValues(httpReq.URL.Query()),
+		Time:      time.Now(),
+	})
+}
+
+// errorReportQueue returns the queue of the reporter set on the Mux or the
+// closest of its parents, if any.
+func (mx *Mux) errorReportQueue() *errorReportQueue {
+	for m := mx; m != nil; m = m.parent {
+		if m.errorReports != nil {
+			return m.errorReports
+		}
+	}
+	return nil
+}
+
+// sensitiveNames lists fragments of header and query string parameter names
+// whose values are scrubbed from error reports.
+var sensitiveNames = []string{"auth", "cookie", "password", "passwd", "secret", "token", "api-key", "api_key", "apikey", "session"}
+
+// scrubValues returns a copy of values, replacing values of sensitive names.
+func scrubValues(values map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(values))
+	for name, v := range values {
+		if isSensitiveName(name) {
+			v = []string{scrubbedValue}
+		} else {
+			v = append([]string{}, v...)
+		}
+		result[name] = v
+	}
+	return result
+}
+
+func isSensitiveName(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryErrorReporter is an ErrorReporter keeping reports in memory, intended
+// for testing purposes.
+type MemoryErrorReporter struct {
+	mu      sync.Mutex
+	reports []ErrorReport
+}
+
+// ReportError stores a given report.
+func (m *MemoryErrorReporter) ReportError(report ErrorReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reports = append(m.reports, report)
+}
+
+// Reports returns all reports received so far.
+func (m *MemoryErrorReporter) Reports() []ErrorReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ErrorReport{}, m.reports...)
+}
@@ -0,0 +1,189 @@
+package raggett
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type blockingErrorReporter struct {
+	release chan struct{}
+	MemoryErrorReporter
+}
+
+func (b *blockingErrorReporter) ReportError(report ErrorReport) {
+	<-b.release
+	b.MemoryErrorReporter.ReportError(report)
+}
+
+func TestErrorReporter(t *testing.T) {
+	reporter := &MemoryErrorReporter{}
+	mx := NewMux(zap.NewNop())
+	mx.SetErrorReporter(reporter, 0)
+	mx.Get("/users/{id}", func(r *struct{ *Request }) error {
+		return fmt.Errorf("database unavailable")
+	})
+	mx.Get("/panic", func(r *struct{ *Request }) error {
+		var m map[string]int
+		m["boom"]++
+		return nil
+	})
+	mx.Get("/forbidden", func(r *struct{ *Request }) error {
+		return Forbidden("")
+	})
+	mx.Get("/missing", func(r *struct{ *Request }) error {
+		r.NotFound()
+		return nil
+	})
+	mx.Get("/unavailable", func(r *struct{ *Request }) error {
+		return ServiceUnavailable("")
+	})
+
+	req := httptest.NewRequest("GET", "/users/12?name=paul&access_token=abc", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	req.Header.Set("User-Agent", "test")
+	mx.ServeHTTP(httptest.NewRecorder(), req)
+	doRequest(mx, "*/*", "GET", "/panic", nil)
+	doRequest(mx, "*/*", "GET", "/forbidden", nil)
+	doRequest(mx, "*/*", "GET", "/missing", nil)
+	doRequest(mx, "*/*", "GET", "/unavailable", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, mx.CloseErrorReporter(ctx))
+
+	reports := reporter.Reports()
+	require.Len(t, reports, 3)
+
+	r := reports[0]
+	assert.EqualError(t, r.Error, "database unavailable")
+	assert.NotEmpty(t, r.Error.StackTrace)
+	assert.Equal(t, http.StatusInternalServerError, r.Status)
+	assert.Equal(t, "/users/{id}", r.Pattern)
+	assert.Equal(t, "GET", r.Method)
+	assert.Equal(t, "/users/12", r.Path)
+	assert.NotEmpty(t, r.RequestID)
+	assert.Equal(t, "[FILTERED]", r.Headers.Get("Authorization"))
+	assert.Equal(t, "test", r.Headers.Get("User-Agent"))
+	assert.Equal(t, "[FILTERED]", r.Query.Get("access_token"))
+	assert.Equal(t, "paul", r.Query.Get("name"))
+	assert.Equal(t, "Bearer abc", req.Header.Get("Authorization"))
+
+	r = reports[1]
+	assert.Equal(t, "/panic", r.Pattern)
+	assert.Contains(t, r.Error.Error(), "assignment to entry in nil map")
+	foundHandler := false
+	for _, f := range r.Error.StackTrace {
+		if strings.Contains(f.Func, "TestErrorReporter") {
+			foundHandler = true
+			break
+		}
+	}
+	assert.True(t, foundHandler, "stack trace must include the panicking handler")
+
+	assert.Equal(t, http.StatusServiceUnavailable, reports[2].Status)
+
+	// Reports are ignored after closing.
+	doRequest(mx, "*/*", "GET", "/panic", nil)
+	assert.Len(t, reporter.Reports(), 3)
+}
+
+func TestErrorReporterBoundedQueue(t *testing.T) {
+	reporter := &blockingErrorReporter{release: make(chan struct{})}
+	mx := NewMux(zap.NewNop())
+	mx.SetErrorReporter(reporter, 1)
+	mx.Get("/", func(r *struct{ *Request }) error {
+		return fmt.Errorf("boom")
+	})
+
+	for i := 0; i < 5; i++ {
+		code, _ := doRequest(mx, "*/*", "GET", "/", nil)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	}
+	close(reporter.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, mx.CloseErrorReporter(ctx))
+
+	// One report may be held by the reporter while another waits in the queue.
+	count := len(reporter.Reports())
+	assert.GreaterOrEqual(t, count, 1)
+	assert.LessOrEqual(t, count, 2)
+}
+
+func TestErrorReporterSubRouter(t *testing.T) {
+	parent := &MemoryErrorReporter{}
+	admin := &MemoryErrorReporter{}
+	mx := NewMux(zap.NewNop())
+	mx.SetErrorReporter(parent, 0)
+	adminMux := mx.Route("/admin", func(r *Mux) {
+		r.SetErrorReporter(admin, 0)
+		r.Get("/", func(r *struct{ *Request }) error {
+			return fmt.Errorf("admin failure")
+		})
+	})
+	mx.Route("/api", func(r *Mux) {
+		r.Get("/", func(r *struct{ *Request }) error {
+			return fmt.Errorf("api failure")
+		})
+	})
+	mx.Get("/", func(r *struct{ *Request }) error {
+		return fmt.Errorf("root failure")
+	})
+
+	// Replaced queues are closed asynchronously; give them time to do so, so
+	// a sub-router closing its parent's queue would be noticed.
+	time.Sleep(20 * time.Millisecond)
+
+	doRequest(mx, "*/*", "GET", "/admin/", nil)
+	doRequest(mx, "*/*", "GET", "/api/", nil)
+	doRequest(mx, "*/*", "GET", "/", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, mx.CloseErrorReporter(ctx))
+	require.NoError(t, adminMux.CloseErrorReporter(ctx))
+
+	require.Len(t, admin.Reports(), 1)
+	assert.EqualError(t, admin.Reports()[0].Error, "admin failure")
+	require.Len(t, parent.Reports(), 2)
+	assert.EqualError(t, parent.Reports()[0].Error, "api failure")
+	assert.EqualError(t, parent.Reports()[1].Error, "root failure")
+}
+
+func TestErrorReporterFormParseFailure(t *testing.T) {
+	reporter := &MemoryErrorReporter{}
+	mx := NewMux(zap.NewNop())
+	mx.SetErrorReporter(reporter, 0)
+	invoked := false
+	mx.Post("/", func(r *struct {
+		*Request
+		Name string `form:"name"`
+	}) error {
+		invoked = true
+		return nil
+	})
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("name=paul"))
+	req.Header.Set("Content-Type", "multipart/form-data")
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.False(t, invoked)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, mx.CloseErrorReporter(ctx))
+
+	require.Len(t, reporter.Reports(), 1)
+	assert.ErrorContains(t, reporter.Reports()[0].Error, "boundary")
+}
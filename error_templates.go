@@ -0,0 +1,86 @@
+package raggett
+
+import (
+	"errors"
+	"fmt"
+	html "html/template"
+	"io/fs"
+	text "text/template"
+
+	"github.com/heyvito/raggett/templates"
+)
+
+// ErrorTemplate represents a template used to render error pages, such as
+// *html/template.Template and *text/template.Template.
+type ErrorTemplate = templates.Template
+
+// SetErrorTemplate overrides the template used by the default handlers for a
+// given slot, named after the constants provided by the templates package
+// (e.g. templates.NotFoundErrorConstrainedHTML). Templates for HTML slots
+// should use html/template, so values are properly escaped. Data passed to
+// each template is described by the *TemplateData types of this package.
+// Templates also apply to sub-routers created through Route, Group and With,
+// even when set after the sub-router; templates set on a sub-router take
+// precedence over its parent's, and do not affect the parent.
+func (mx *Mux) SetErrorTemplate(name string, tmpl ErrorTemplate) error {
+	if !templates.Exists(name) {
+		return fmt.Errorf("%w: %s", ErrUnknownErrorTemplate, name)
+	}
+	if tmpl == nil {
+		return fmt.Errorf("raggett: nil template provided for %s", name)
+	}
+
+	if mx.errorTemplates == nil {
+		mx.errorTemplates = map[string]templates.Executor{}
+	}
+	mx.errorTemplates[name] = templates.NewExecutor(tmpl)
+	return nil
+}
+
+// LoadErrorTemplates overrides templates using files of fsys named after the
+// slots they replace (e.g. "not_found_constrained.html"). Files ending in
+// .html are parsed using html/template, and .txt files using text/template.
+// Slots without a matching file keep their current template. Returns an error
+// in case fsys does not provide any template.
+func (mx *Mux) LoadErrorTemplates(fsys fs.FS) error {
+	found := false
+	for _, name := range templates.Names() {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var tmpl ErrorTemplate
+		if templates.IsHTML(name) {
+			tmpl, err = html.New(name).Parse(string(data))
+		} else {
+			tmpl, err = text.New(name).Parse(string(data))
+		}
+		if err != nil {
+			return fmt.Errorf("raggett: failed parsing template %s: %w", name, err)
+		}
+		if err = mx.SetErrorTemplate(name, tmpl); err != nil {
+			return err
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("raggett: no error templates found")
+	}
+	return nil
+}
+
+// executeErrorTemplate renders the template set for a given slot on the Mux or
+// the closest of its parents, falling back to the one provided by the
+// templates package.
+func (mx *Mux) executeErrorTemplate(name string, data interface{}) (string, error) {
+	for m := mx; m != nil; m = m.parent {
+		if exec, ok := m.errorTemplates[name]; ok {
+			return exec(data)
+		}
+	}
+	return templates.TemplateNamed(name)(data)
+}
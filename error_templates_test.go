@@ -0,0 +1,143 @@
+package raggett
+
+import (
+	html "html/template"
+	"net/http"
+	"testing"
+	"testing/fstest"
+	text "text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/heyvito/raggett/templates"
+)
+
+func TestSetErrorTemplate(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Development = false
+	mx.Get("/forbidden", func(r *struct{ *Request }) error {
+		return Forbidden("<no access>")
+	})
+
+	notFound := html.Must(html.New("").Parse(`<h1>Lost? {{ .Code }} {{ .Message }}</h1>`))
+	require.NoError(t, mx.SetErrorTemplate(templates.NotFoundErrorConstrainedHTML, notFound))
+	serverError := text.Must(text.New("").Parse(`{{ .Code }} {{ .Message }} ({{ .RequestID }})`))
+	require.NoError(t, mx.SetErrorTemplate(templates.ServerErrorConstrainedText, serverError))
+	forbidden := html.Must(html.New("").Parse(`<p>{{ .Message }}</p>`))
+	require.NoError(t, mx.SetErrorTemplate(templates.ServerErrorConstrainedHTML, forbidden))
+
+	code, body := doRequest(mx, "text/html", "GET", "/missing", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "<h1>Lost? 404 The requested resource was not found.</h1>", body)
+
+	code, body = doRequest(mx, "text/plain", "GET", "/forbidden", nil)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Regexp(t, `^403 <no access> \(.+\)$`, body)
+
+	_, body = doRequest(mx, "text/html", "GET", "/forbidden", nil)
+	assert.Equal(t, "<p>&lt;no access&gt;</p>", body)
+
+	_, body = doRequest(mx, "text/plain", "GET", "/missing", nil)
+	assert.Contains(t, body, "The requested resource was not found.")
+
+	err := mx.SetErrorTemplate("missing.html", notFound)
+	assert.ErrorIs(t, err, ErrUnknownErrorTemplate)
+}
+
+func TestSetErrorTemplateSubRouter(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Development = false
+	tmpl := text.Must(text.New("").Parse(`custom {{ .Code }}`))
+	require.NoError(t, mx.SetErrorTemplate(templates.ServerErrorConstrainedText, tmpl))
+
+	mx.Route("/admin", func(r *Mux) {
+		sub := text.Must(text.New("").Parse(`admin {{ .Code }}`))
+		require.NoError(t, r.SetErrorTemplate(templates.ServerErrorConstrainedText, sub))
+		r.Get("/", func(r *struct{ *Request }) error {
+			return Conflict("")
+		})
+	})
+	mx.Get("/", func(r *struct{ *Request }) error {
+		return Conflict("")
+	})
+
+	_, body := doRequest(mx, "text/plain", "GET", "/admin/", nil)
+	assert.Equal(t, "admin 409", body)
+
+	_, body = doRequest(mx, "text/plain", "GET", "/", nil)
+	assert.Equal(t, "custom 409", body)
+}
+
+func TestSetErrorTemplateSubRouterNotFound(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Development = false
+	mx.Route("/api", func(r *Mux) {
+		tmpl := text.Must(text.New("").Parse(`api {{ .Code }} {{ .Message }}`))
+		require.NoError(t, r.SetErrorTemplate(templates.NotFoundErrorConstrainedText, tmpl))
+		r.Get("/items", func(r *struct{ *Request }) error {
+			return nil
+		})
+	})
+
+	code, body := doRequest(mx, "text/plain", "GET", "/api/missing", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "api 404 The requested resource was not found.", body)
+
+	code, body = doRequest(mx, "text/plain", "POST", "/api/items", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "api 405 This endpoint does not allow this HTTP method.", body)
+
+	code, body = doRequest(mx, "text/plain", "GET", "/missing", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.NotContains(t, body, "api")
+}
+
+func TestSetErrorTemplateAfterRoutes(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Development = false
+	mx.Route("/admin", func(r *Mux) {
+		r.Get("/", func(r *struct{ *Request }) error {
+			return Conflict("")
+		})
+	})
+	tmpl := text.Must(text.New("").Parse(`late {{ .Code }}`))
+	require.NoError(t, mx.SetErrorTemplate(templates.ServerErrorConstrainedText, tmpl))
+
+	_, body := doRequest(mx, "text/plain", "GET", "/admin/", nil)
+	assert.Equal(t, "late 409", body)
+}
+
+func TestLoadErrorTemplates(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		mx := NewMux(zap.NewNop())
+		mx.Development = false
+		err := mx.LoadErrorTemplates(fstest.MapFS{
+			"not_found_constrained.html": {Data: []byte(`<b>{{ .Message }}</b>`)},
+			"not_found_constrained.txt":  {Data: []byte(`<{{ .Code }}>`)},
+			"unrelated.md":               {Data: []byte(`ignored`)},
+		})
+		require.NoError(t, err)
+
+		_, body := doRequest(mx, "text/html", "GET", "/missing", nil)
+		assert.Equal(t, "<b>The requested resource was not found.</b>", body)
+
+		_, body = doRequest(mx, "text/plain", "GET", "/missing", nil)
+		assert.Equal(t, "<404>", body)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		mx := NewMux(zap.NewNop())
+		err := mx.LoadErrorTemplates(fstest.MapFS{
+			"error.html": {Data: []byte(`{{ .Code `)},
+		})
+		assert.ErrorContains(t, err, "error.html")
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		mx := NewMux(zap.NewNop())
+		err := mx.LoadErrorTemplates(fstest.MapFS{})
+		assert.Error(t, err)
+	})
+}
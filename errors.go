@@ -20,6 +20,24 @@ func (v ValidationErrorKind) String() string {
 		return "is required"
 	case ValidationErrorKindParsing:
 		return "is invalid"
+	case ValidationErrorKindMin:
+		return "is below the minimum value"
+	case ValidationErrorKindMax:
+		return "is above the maximum value"
+	case ValidationErrorKindMinLength:
+		return "is too short"
+	case ValidationErrorKindMaxLength:
+		return "is too long"
+	case ValidationErrorKindOneOf:
+		return "is not one of the allowed values"
+	case ValidationErrorKindMinItems:
+		return "has too few items"
+	case ValidationErrorKindMaxItems:
+		return "has too many items"
+	case ValidationErrorKindCustom:
+		return "failed validation"
+	case ValidationErrorKindType:
+		return "has an unexpected type"
 	default:
 		return fmt.Sprintf("«Error: Unexpected ValidationErrorKind %d»", v)
 	}
@@ -35,6 +53,24 @@ func (v ValidationErrorKind) Name() string {
 		return "ValidationErrorKindRequired"
 	case ValidationErrorKindParsing:
 		return "ValidationErrorKindParsing"
+	case ValidationErrorKindMin:
+		return "ValidationErrorKindMin"
+	case ValidationErrorKindMax:
+		return "ValidationErrorKindMax"
+	case ValidationErrorKindMinLength:
+		return "ValidationErrorKindMinLength"
+	case ValidationErrorKindMaxLength:
+		return "ValidationErrorKindMaxLength"
+	case ValidationErrorKindOneOf:
+		return "ValidationErrorKindOneOf"
+	case ValidationErrorKindMinItems:
+		return "ValidationErrorKindMinItems"
+	case ValidationErrorKindMaxItems:
+		return "ValidationErrorKindMaxItems"
+	case ValidationErrorKindCustom:
+		return "ValidationErrorKindCustom"
+	case ValidationErrorKindType:
+		return "ValidationErrorKindType"
 	default:
 		return fmt.Sprintf("«Error: Unexpected ValidationErrorKind %d»", v)
 	}
@@ -45,6 +81,15 @@ const (
 	ValidationErrorKindPattern
 	ValidationErrorKindRequired
 	ValidationErrorKindParsing
+	ValidationErrorKindMin
+	ValidationErrorKindMax
+	ValidationErrorKindMinLength
+	ValidationErrorKindMaxLength
+	ValidationErrorKindOneOf
+	ValidationErrorKindMinItems
+	ValidationErrorKindMaxItems
+	ValidationErrorKindCustom
+	ValidationErrorKindType
 )
 
 type ValidationError struct {
@@ -54,12 +99,57 @@ type ValidationError struct {
 	FieldKind       fieldKind
 	ErrorKind       ValidationErrorKind
 	OriginalError   error
+	// Constraint describes the constraint violated by the field's value, such
+	// as "min=1" or "oneof=a|b", when ErrorKind refers to a constraint tag.
+	Constraint string
+	// StatusCode indicates the HTTP status used by the default validation
+	// error handlers to respond to the client. Zero indicates
+	// http.StatusBadRequest.
+	StatusCode int
+}
+
+// FieldError returns a ValidationError indicating that the value of a given
+// field is invalid, to be returned by Validator implementations. field may
+// either be the name used by the field's resolver tag (for instance, "email"
+// in `query:"email"`), or the name of the struct field. An empty field
+// indicates that the error does not refer to a single field.
+func FieldError(field string, err error) ValidationError {
+	return ValidationError{
+		FieldName:     field,
+		FieldKind:     fieldKindStruct,
+		ErrorKind:     ValidationErrorKindCustom,
+		OriginalError: err,
+	}
 }
 
 func (v ValidationError) Error() string {
+	if v.ErrorKind == ValidationErrorKindCustom && v.OriginalError != nil {
+		if v.FieldName == "" {
+			return fmt.Sprintf("Validation of %s failed: %s", v.StructName, v.OriginalError)
+		}
+		return fmt.Sprintf("Validation of %s failed: Value for field %s %s: %s", v.StructName, v.FieldName, v.ErrorKind, v.OriginalError)
+	}
+	if v.Constraint != "" {
+		return fmt.Sprintf("Validation of %s failed: Value for field %s %s (%s)", v.StructName, v.FieldName, v.ErrorKind, v.Constraint)
+	}
 	return fmt.Sprintf("Validation of %s failed: Value for field %s %s", v.StructName, v.FieldName, v.ErrorKind)
 }
 
+// ValidationErrors aggregates every ValidationError found while loading a
+// request when Mux.CollectValidationErrors is set.
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	if len(v) == 1 {
+		return v[0].Error()
+	}
+	msgs := make([]string, 0, len(v))
+	for _, e := range v {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(v), strings.Join(msgs, "; "))
+}
+
 type Error struct {
 	StackTrace    []StackFrame
 	OriginalError error
@@ -69,6 +159,12 @@ func (e Error) Error() string {
 	return e.OriginalError.Error()
 }
 
+// Unwrap returns the original error, allowing errors.Is and errors.As to
+// inspect it.
+func (e Error) Unwrap() error {
+	return e.OriginalError
+}
+
 func (e Error) Stack() string {
 	trace := make([]string, 0, len(e.StackTrace))
 	for _, t := range e.StackTrace {
@@ -85,15 +181,33 @@ func (e Error) String() string {
 // Internal
 
 var errAbortRequest = fmt.Errorf("__raggett_abort_request")
-var errAbortNotFound = fmt.Errorf("__raggett_abort_request_not_found")
+
+var errUnsupportedMediaType = fmt.Errorf("unsupported media type")
+
+var errNilContentBody = fmt.Errorf("content provided to RespondContent has a nil Body")
+
+var errDefaultNotSupported = fmt.Errorf("default values are not supported for body and file fields")
+
+var errConstraintNotSupported = fmt.Errorf("constraints are not supported for body and file fields")
+
+var errMapDefaultNotSupported = fmt.Errorf("default values are not supported for map fields")
+
+///////////
+// Templates
+
+// ErrUnknownErrorTemplate indicates that a template name provided to
+// Mux.SetErrorTemplate does not refer to any template slot.
+var ErrUnknownErrorTemplate = fmt.Errorf("unknown error template")
 
 ///////////
 // Reflect
 
 var ErrNoFunction = fmt.Errorf("handler must be a function")
 var ErrIncorrectArgsArity = fmt.Errorf("invalid arguments arity. Expected 1")
-var ErrIncorrectOutArity = fmt.Errorf("invalid returns arity. Expected 1")
+var ErrIncorrectOutArity = fmt.Errorf("invalid returns arity. Expected 1 or 2")
+var ErrIncorrectOutType = fmt.Errorf("invalid return type. Last returned value must be an error")
 var ErrIncorrectArgType = fmt.Errorf("invalid argument type. Must be a struct using a *raggett.Request promoted structField")
+var ErrIncorrectResponseType = fmt.Errorf("invalid response type. First returned value must be an interface or implement a responder interface, such as JSONResponder")
 
 //+errGen:ErrCustomRequestParserConflict(structName reflect.Type->Name())
 //		  msg: invalid structure definition for \(structName): Can't implement CustomRequestParser and have forms or body fields
@@ -113,13 +227,13 @@ var ErrIncorrectArgType = fmt.Errorf("invalid argument type. Must be a struct us
 // ErrFieldsWithoutResolver indicates that a given structure has inconsistent
 // fields using one or more validator tags without using a resolver tag. In
 // order to use validations, a resolver must be used. Resolvers are tags such as
-// `url-param`, `query`, `body`, `form`, or `header`.
+// `url-param`, `query`, `body`, `form`, `header`, `cookie`, or `context`.
 
 //+errGen:ErrMultipleResolver(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
 //        msg: invalid structure definition for \(structName): Field \(fieldName) uses more than one value resolver; url-param, query, and body must be used only once per struct field
 // ErrMultipleResolver indicates that a given structure has a field using more
 // than one resolver. Fields must not have more than one of the following tags:
-// `url-param`, `query`, `body`, `form`, `header`.
+// `url-param`, `query`, `body`, `form`, `header`, `cookie`, `context`.
 
 //+errGen:ErrEmptyPattern(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
 //        msg: invalid structure definition for \(structName): Field \(fieldName) has empty regexp pattern
@@ -146,7 +260,14 @@ var ErrIncorrectArgType = fmt.Errorf("invalid argument type. Must be a struct us
 //        msg: invalid structure definition for \(structName): Field \(fieldName) contains an invalid body parser.
 // ErrInvalidBodyParser indicates that a given structure has a field using a
 // `body` loader with an invalid parser. Valid values for that tag are `json`,
-// `xml`, `text`, `stream`, or `bytes`.
+// `xml`, `text`, `stream`, `bytes`, or the name of a parser registered through
+// RegisterBodyParser.
+
+//+errGen:ErrAutoBodyParserUnavailable(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
+//        msg: invalid structure definition for \(structName): Field \(fieldName) uses an auto body, but no parser accepts its type.
+// ErrAutoBodyParserUnavailable indicates that a given structure has a field
+// using `body:"auto"` whose type is not accepted by any body parser providing
+// media types.
 
 //+errGen:ErrInvalidFileField(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
 //        msg: invalid structure definition for \(structName): Field \(fieldName) must use a pointer to multipart.FileHeader or raggett.FileHeader
@@ -155,4 +276,49 @@ var ErrIncorrectArgType = fmt.Errorf("invalid argument type. Must be a struct us
 // the file field be either a pointer, or a pointer slice of multipart.FileHeader
 // or raggett.FileHeader, the latter being an alias to the former.
 
+//+errGen:ErrUnsupportedFieldType(structName reflect.Type->Name(), fieldName reflect.StructField->Name, fieldType reflect.Type->String())
+//        msg: invalid structure definition for \(structName): Field \(fieldName) has unsupported type \(fieldType)
+// ErrUnsupportedFieldType indicates that a given structure has a field using
+// a `url-param`, `query`, `form`, or `header` resolver with a type that cannot
+// be parsed from a string. Supported types are primitives, time.Time,
+// time.Duration, *url.URL, types implementing encoding.TextUnmarshaler (such as
+// uuid.UUID and net.IP), and slices of those.
+
+//+errGen:ErrInvalidFormatTag(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
+//        msg: invalid structure definition for \(structName): Field \(fieldName) uses a format tag, but is not a time.Time
+// ErrInvalidFormatTag indicates that a given structure has a field using a
+// `format` tag with an empty value, or with a type other than time.Time or a
+// slice of time.Time.
+
+//+errGen:ErrInvalidSplitTag(structName reflect.Type->Name(), fieldName reflect.StructField->Name)
+//        msg: invalid structure definition for \(structName): Field \(fieldName) uses a split tag, but is not a slice
+// ErrInvalidSplitTag indicates that a given structure has a field using a
+// `split` tag with an empty value, or with a type other than a slice.
+
+//+errGen:ErrInvalidContextField(structName reflect.Type->Name(), fieldName reflect.StructField->Name, err error->Error())
+//        msg: invalid structure definition for \(structName): Field \(fieldName) cannot be loaded from the request context: \(err)
+// ErrInvalidContextField indicates that a given structure has a field using
+// the `context` tag along with tags other than `required`, with a name not
+// registered through NewContextKey, or with a type incompatible with the
+// ContextKey registered with the same name.
+
+//+errGen:ErrInvalidDefault(structName reflect.Type->Name(), fieldName reflect.StructField->Name, err error->Error())
+//        msg: invalid structure definition for \(structName): Field \(fieldName) has an invalid default value: \(err)
+// ErrInvalidDefault indicates that a given structure has a field using a
+// `default` tag whose value cannot be parsed into the field's type, or that is
+// used along with a `body` resolver or a file field.
+
+//+errGen:ErrInvalidConstraint(structName reflect.Type->Name(), fieldName reflect.StructField->Name, err error->Error())
+//        msg: invalid structure definition for \(structName): Field \(fieldName) has an invalid constraint: \(err)
+// ErrInvalidConstraint indicates that a given structure has a field using one
+// of the `min`, `max`, `minlen`, `maxlen`, `oneof`, `minitems`, or `maxitems`
+// tags with an invalid value, or with a type it cannot be applied to.
+
+//+errGen:ErrInvalidNestedField(structName reflect.Type->Name(), fieldName reflect.StructField->Name, err error->Error())
+//        msg: invalid structure definition for \(structName): Field \(fieldName) cannot be bound as a nested field: \(err)
+// ErrInvalidNestedField indicates that a given structure binds a nested struct
+// through the `query` or `form` tags in an unsupported way, such as having
+// fields using a different resolver, or validation tags applied to the nested
+// struct itself.
+
 //go:generate go run generators/errors/generate_errors.go
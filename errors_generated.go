@@ -43,7 +43,7 @@ func errBodyFormsConflict(structName reflect.Type) error {
 // ErrFieldsWithoutResolver indicates that a given structure has inconsistent
 // fields using one or more validator tags without using a resolver tag. In
 // order to use validations, a resolver must be used. Resolvers are tags such as
-// `url-param`, `query`, `body`, `form`, or `header`.
+// `url-param`, `query`, `body`, `form`, `header`, `cookie`, or `context`.
 type ErrFieldsWithoutResolver struct {
 	structName string
 	fieldName  string
@@ -61,7 +61,7 @@ func errFieldsWithoutResolver(structName reflect.Type, fieldName reflect.StructF
 
 // ErrMultipleResolver indicates that a given structure has a field using more
 // than one resolver. Fields must not have more than one of the following tags:
-// `url-param`, `query`, `body`, `form`, `header`.
+// `url-param`, `query`, `body`, `form`, `header`, `cookie`, `context`.
 type ErrMultipleResolver struct {
 	structName string
 	fieldName  string
@@ -148,7 +148,8 @@ func errEmptyBodyTag(structName reflect.Type, fieldName reflect.StructField) err
 
 // ErrInvalidBodyParser indicates that a given structure has a field using a
 // `body` loader with an invalid parser. Valid values for that tag are `json`,
-// `xml`, `text`, `stream`, or `bytes`.
+// `xml`, `text`, `stream`, `bytes`, or the name of a parser registered through
+// RegisterBodyParser.
 type ErrInvalidBodyParser struct {
 	structName string
 	fieldName  string
@@ -164,6 +165,24 @@ func errInvalidBodyParser(structName reflect.Type, fieldName reflect.StructField
 	}
 }
 
+// ErrAutoBodyParserUnavailable indicates that a given structure has a field
+// using `body:"auto"` whose type is not accepted by any body parser providing
+// media types.
+type ErrAutoBodyParserUnavailable struct {
+	structName string
+	fieldName  string
+}
+
+func (e ErrAutoBodyParserUnavailable) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s uses an auto body, but no parser accepts its type.", e.structName, e.fieldName)
+}
+func errAutoBodyParserUnavailable(structName reflect.Type, fieldName reflect.StructField) error {
+	return ErrAutoBodyParserUnavailable{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+	}
+}
+
 // ErrInvalidFileField indicates that a given structure has a field attempting
 // to receive a File from the request, but has an invalid type. Raggett expects
 // the file field be either a pointer, or a pointer slice of multipart.FileHeader
@@ -182,3 +201,142 @@ func errInvalidFileField(structName reflect.Type, fieldName reflect.StructField)
 		fieldName:  fieldName.Name,
 	}
 }
+
+// ErrUnsupportedFieldType indicates that a given structure has a field using
+// a `url-param`, `query`, `form`, or `header` resolver with a type that cannot
+// be parsed from a string. Supported types are primitives, time.Time,
+// time.Duration, *url.URL, types implementing encoding.TextUnmarshaler (such as
+// uuid.UUID and net.IP), and slices of those.
+type ErrUnsupportedFieldType struct {
+	structName string
+	fieldName  string
+	fieldType  string
+}
+
+func (e ErrUnsupportedFieldType) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s has unsupported type %s", e.structName, e.fieldName, e.fieldType)
+}
+func errUnsupportedFieldType(structName reflect.Type, fieldName reflect.StructField, fieldType reflect.Type) error {
+	return ErrUnsupportedFieldType{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+		fieldType:  fieldType.String(),
+	}
+}
+
+// ErrInvalidFormatTag indicates that a given structure has a field using a
+// `format` tag with an empty value, or with a type other than time.Time or a
+// slice of time.Time.
+type ErrInvalidFormatTag struct {
+	structName string
+	fieldName  string
+}
+
+func (e ErrInvalidFormatTag) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s uses a format tag, but is not a time.Time", e.structName, e.fieldName)
+}
+func errInvalidFormatTag(structName reflect.Type, fieldName reflect.StructField) error {
+	return ErrInvalidFormatTag{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+	}
+}
+
+// ErrInvalidSplitTag indicates that a given structure has a field using a
+// `split` tag with an empty value, or with a type other than a slice.
+type ErrInvalidSplitTag struct {
+	structName string
+	fieldName  string
+}
+
+func (e ErrInvalidSplitTag) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s uses a split tag, but is not a slice", e.structName, e.fieldName)
+}
+func errInvalidSplitTag(structName reflect.Type, fieldName reflect.StructField) error {
+	return ErrInvalidSplitTag{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+	}
+}
+
+// ErrInvalidContextField indicates that a given structure has a field using
+// the `context` tag along with tags other than `required`, with a name not
+// registered through NewContextKey, or with a type incompatible with the
+// ContextKey registered with the same name.
+type ErrInvalidContextField struct {
+	structName string
+	fieldName  string
+	err        string
+}
+
+func (e ErrInvalidContextField) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s cannot be loaded from the request context: %s", e.structName, e.fieldName, e.err)
+}
+func errInvalidContextField(structName reflect.Type, fieldName reflect.StructField, err error) error {
+	return ErrInvalidContextField{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+		err:        err.Error(),
+	}
+}
+
+// ErrInvalidDefault indicates that a given structure has a field using a
+// `default` tag whose value cannot be parsed into the field's type, or that is
+// used along with a `body` resolver or a file field.
+type ErrInvalidDefault struct {
+	structName string
+	fieldName  string
+	err        string
+}
+
+func (e ErrInvalidDefault) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s has an invalid default value: %s", e.structName, e.fieldName, e.err)
+}
+func errInvalidDefault(structName reflect.Type, fieldName reflect.StructField, err error) error {
+	return ErrInvalidDefault{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+		err:        err.Error(),
+	}
+}
+
+// ErrInvalidConstraint indicates that a given structure has a field using one
+// of the `min`, `max`, `minlen`, `maxlen`, `oneof`, `minitems`, or `maxitems`
+// tags with an invalid value, or with a type it cannot be applied to.
+type ErrInvalidConstraint struct {
+	structName string
+	fieldName  string
+	err        string
+}
+
+func (e ErrInvalidConstraint) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s has an invalid constraint: %s", e.structName, e.fieldName, e.err)
+}
+func errInvalidConstraint(structName reflect.Type, fieldName reflect.StructField, err error) error {
+	return ErrInvalidConstraint{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+		err:        err.Error(),
+	}
+}
+
+// ErrInvalidNestedField indicates that a given structure binds a nested struct
+// through the `query` or `form` tags in an unsupported way, such as having
+// fields using a different resolver, or validation tags applied to the nested
+// struct itself.
+type ErrInvalidNestedField struct {
+	structName string
+	fieldName  string
+	err        string
+}
+
+func (e ErrInvalidNestedField) Error() string {
+	return fmt.Sprintf("invalid structure definition for %s: Field %s cannot be bound as a nested field: %s", e.structName, e.fieldName, e.err)
+}
+func errInvalidNestedField(structName reflect.Type, fieldName reflect.StructField, err error) error {
+	return ErrInvalidNestedField{
+		structName: structName.Name(),
+		fieldName:  fieldName.Name,
+		err:        err.Error(),
+	}
+}
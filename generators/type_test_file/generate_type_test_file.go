@@ -76,6 +76,10 @@ func main() {
 		"string": func(s string) string {
 			return `"` + s + `"`
 		},
+		// Large floats must be emitted as float literals; integer literals
+		// beyond 512 bits overflow the compiler's constant representation.
+		"float32": floatLiteral(32),
+		"float64": floatLiteral(64),
 	}
 
 	output := &Builder{}
@@ -258,6 +262,16 @@ func main() {
 	}
 }
 
+func floatLiteral(bitSize int) func(s string) string {
+	return func(s string) string {
+		f, err := strconv.ParseFloat(s, bitSize)
+		if err != nil {
+			panic(err)
+		}
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	}
+}
+
 func createAssessment(o *Builder, requestParamName, structFieldName, typeName string, value []string) {
 	o.WriteFormat("t.Run(`Received %s equals %s`, func(t *testing.T) {", requestParamName, value[0])
 	if len(value) == 1 {
@@ -0,0 +1,144 @@
+package raggett
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// HTTPError represents an error associated with an HTTP status. Handlers may
+// return an *HTTPError to have the default error handler respond with its
+// Status, Message and Headers, instead of a generic 500 response:
+//
+//	if !user.CanEdit(doc) {
+//		return raggett.Forbidden("You cannot edit this document.")
+//	}
+type HTTPError struct {
+	// Status is the HTTP status code of the response. Defaults to 500.
+	Status int
+	// Message is a human-readable message safe to be sent to clients. Defaults
+	// to the text of Status.
+	Message string
+	// Cause is the internal error that caused this error, if any. It is logged,
+	// but only sent to clients when Mux.Development is set.
+	Cause error
+	// Headers contains additional headers to be sent along with the response.
+	Headers http.Header
+}
+
+// NewHTTPError returns a new HTTPError with the provided status and message.
+func NewHTTPError(status int, message string) *HTTPError {
+	return &HTTPError{Status: status, Message: message}
+}
+
+// BadRequest returns a new HTTPError with status 400 and the provided message.
+func BadRequest(message string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, message)
+}
+
+// Unauthorized returns a new HTTPError with status 401 and the provided
+// message.
+func Unauthorized(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, message)
+}
+
+// Forbidden returns a new HTTPError with status 403 and the provided message.
+func Forbidden(message string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, message)
+}
+
+// NotFound returns a new HTTPError with status 404 and the provided message.
+func NotFound(message string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, message)
+}
+
+// Conflict returns a new HTTPError with status 409 and the provided message.
+func Conflict(message string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, message)
+}
+
+// Gone returns a new HTTPError with status 410 and the provided message.
+func Gone(message string) *HTTPError {
+	return NewHTTPError(http.StatusGone, message)
+}
+
+// UnprocessableEntity returns a new HTTPError with status 422 and the provided
+// message.
+func UnprocessableEntity(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnprocessableEntity, message)
+}
+
+// TooManyRequests returns a new HTTPError with status 429 and the provided
+// message.
+func TooManyRequests(message string) *HTTPError {
+	return NewHTTPError(http.StatusTooManyRequests, message)
+}
+
+// ServiceUnavailable returns a new HTTPError with status 503 and the provided
+// message.
+func ServiceUnavailable(message string) *HTTPError {
+	return NewHTTPError(http.StatusServiceUnavailable, message)
+}
+
+// Wrap sets the internal cause of the error, returning the error itself.
+func (e *HTTPError) Wrap(cause error) *HTTPError {
+	e.Cause = cause
+	return e
+}
+
+// WithHeader adds a header to be sent along with the response, returning the
+// error itself.
+func (e *HTTPError) WithHeader(name, value string) *HTTPError {
+	if e.Headers == nil {
+		e.Headers = http.Header{}
+	}
+	e.Headers.Add(name, value)
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	msg := e.message()
+	if e.Cause == nil {
+		return msg
+	}
+	return msg + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the internal cause of the error.
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
+}
+
+func (e *HTTPError) status() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+func (e *HTTPError) message() string {
+	if e.Message == "" {
+		return http.StatusText(e.status())
+	}
+	return e.Message
+}
+
+// asHTTPError returns the HTTPError wrapped by a given error, if any.
+func asHTTPError(err error) (*HTTPError, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr != nil {
+		return httpErr, true
+	}
+	return nil, false
+}
+
+// logRuntimeError logs an error returned by a handler. Client errors are
+// logged as warnings, while any other status is logged as an error.
+func logRuntimeError(r *Request, status int, err error) {
+	if status >= 400 && status < 500 {
+		r.Logger.Warn("Client error serving request", zap.Int("status", status), zap.Error(err))
+		return
+	}
+	r.Logger.Error("Runtime error serving request", zap.Int("status", status), zap.Error(err))
+}
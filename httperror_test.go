@@ -0,0 +1,135 @@
+package raggett
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHTTPError(t *testing.T) {
+	cause := fmt.Errorf("row locked")
+	err := Conflict("Document is being edited.").Wrap(cause)
+	assert.Equal(t, http.StatusConflict, err.Status)
+	assert.Equal(t, "Document is being edited.: row locked", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	assert.Equal(t, "Forbidden", Forbidden("").Error())
+	assert.Equal(t, http.StatusInternalServerError, (&HTTPError{}).status())
+
+	wrapped, ok := asHTTPError(makeError(fmt.Errorf("saving: %w", err)))
+	assert.True(t, ok)
+	assert.Same(t, err, wrapped)
+}
+
+func TestHTTPErrorResponses(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	mx := NewMux(zap.New(core))
+	mx.Development = false
+	mx.Get("/forbidden", func(r *struct{ *Request }) error {
+		return Forbidden("You cannot edit this document.").
+			Wrap(fmt.Errorf("user 42 is not an editor")).
+			WithHeader("X-Reason", "permissions")
+	})
+	mx.Get("/unavailable", func(r *struct{ *Request }) error {
+		return fmt.Errorf("loading: %w", ServiceUnavailable("").WithHeader("Retry-After", "30"))
+	})
+	mx.Get("/missing", func(r *struct{ *Request }) error {
+		r.NotFound()
+		return nil
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/forbidden", nil)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "permissions", w.Header().Get("X-Reason"))
+		assert.Contains(t, w.Body.String(), `"message":"You cannot edit this document."`)
+		assert.NotContains(t, w.Body.String(), "user 42")
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		code, body := doRequest(mx, "text/plain", "GET", "/forbidden", nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		assert.Contains(t, body, "You cannot edit this document.")
+		assert.NotContains(t, body, "internal server error")
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/unavailable", nil)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "30", w.Header().Get("Retry-After"))
+		assert.Contains(t, w.Body.String(), "<p>Service Unavailable</p>")
+	})
+
+	t.Run("Request NotFound", func(t *testing.T) {
+		code, body := doRequest(mx, "text/plain", "GET", "/missing", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		assert.Contains(t, body, notFoundConstrainedValue.Message)
+	})
+
+	t.Run("Log Level", func(t *testing.T) {
+		logs.TakeAll()
+		doRequest(mx, "text/plain", "GET", "/forbidden", nil)
+		entries := logs.FilterMessage("Client error serving request").All()
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		}
+
+		doRequest(mx, "text/plain", "GET", "/unavailable", nil)
+		assert.Len(t, logs.FilterMessage("Runtime error serving request").FilterField(zap.Int("status", 503)).All(), 1)
+	})
+}
+
+func TestHTTPErrorProblemDetails(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.ProblemDetails = true
+	mx.Development = false
+	mx.Get("/conflict", func(r *struct{ *Request }) error {
+		return Conflict("Document is being edited.")
+	})
+
+	w, body := doProblemRequest(mx, "application/json", "GET", "/conflict")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "Conflict", body["title"])
+	assert.Equal(t, "Document is being edited.", body["detail"])
+}
+
+func TestHTTPErrorUnsupportedMediaTypeNotShared(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.HandleError(func(err error, w http.ResponseWriter, r *Request) {
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Empty(t, httpErr.Headers)
+		httpErr.WithHeader("Accept", "application/json")
+		w.WriteHeader(httpErr.Status)
+	})
+	mx.Post("/", func(r *struct {
+		*Request
+		Data struct {
+			Name string `json:"name"`
+		} `body:"auto"`
+	}) error {
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/", strings.NewReader("name"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	}
+}
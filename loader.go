@@ -1,11 +1,13 @@
 package raggett
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"unsafe"
@@ -94,29 +96,23 @@ func doPrimitiveCoercion(val string, kind reflect.Kind, into reflect.Value) erro
 
 func doCoercion(val []string, field *requestField, inst reflect.Value) error {
 	instField := inst.FieldByIndex(field.structField.Index)
-	switch field.structField.Type.Kind() {
-	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.String:
+	if !field.isSlice {
 		if len(val) == 0 {
 			return nil
 		}
-		err := doPrimitiveCoercion(val[0], field.structField.Type.Kind(), instField)
-		if err != nil {
+		if err := field.coercer(val[0], instField); err != nil {
 			return makeError(err)
 		}
-	// Slices
-	case reflect.Slice:
-		innerType := field.structField.Type.Elem()
-		newSlice := reflect.MakeSlice(field.structField.Type, len(val), len(val))
-		for i, v := range val {
-			inst := reflect.New(innerType).Elem()
-			if err := doPrimitiveCoercion(v, innerType.Kind(), inst); err != nil {
-				return err
-			}
-			newSlice.Index(i).Set(inst)
-		}
-		instField.Set(newSlice)
+		return nil
 	}
 
+	newSlice := reflect.MakeSlice(field.structField.Type, len(val), len(val))
+	for i, v := range val {
+		if err := field.coercer(v, newSlice.Index(i)); err != nil {
+			return err
+		}
+	}
+	instField.Set(newSlice)
 	return nil
 }
 
@@ -128,7 +124,7 @@ func makeValidationErrorWithError(fieldKind fieldKind, errorKind ValidationError
 
 	return ValidationError{
 		StructName:      field.requestMetadata.structType.Name(),
-		StructFieldName: field.structField.Name,
+		StructFieldName: field.structPath,
 		FieldName:       field.requestFieldName,
 		FieldKind:       fieldKind,
 		ErrorKind:       errorKind,
@@ -140,7 +136,23 @@ func makeValidationError(fieldKind fieldKind, errorKind ValidationErrorKind, fie
 	return makeValidationErrorWithError(fieldKind, errorKind, field, nil)
 }
 
+// applyValues looks up and applies values for a given query or form field.
+func applyValues(values map[string][]string, fieldKind fieldKind, field *requestField, inst reflect.Value) error {
+	if field.isMap {
+		val, exists := field.lookupMap(values)
+		return applyMapParam(exists, val, fieldKind, field, inst)
+	}
+	val, exists := field.lookupValues(values)
+	return applyParam(exists, val, fieldKind, field, inst)
+}
+
 func applyParam(exists bool, value []string, fieldKind fieldKind, field *requestField, inst reflect.Value) error {
+	if !exists && field.defaultValue != nil {
+		exists = true
+		value = field.defaultValue
+	}
+	value = field.splitValues(value)
+
 	if field.required != nil && *field.required && !exists {
 		return makeValidationError(fieldKind, ValidationErrorKindRequired, field)
 	}
@@ -164,6 +176,57 @@ func applyParam(exists bool, value []string, fieldKind fieldKind, field *request
 	if err := doCoercion(value, field, inst); err != nil {
 		return makeValidationErrorWithError(fieldKind, ValidationErrorKindParsing, field, err)
 	}
+
+	if exists {
+		kind, constraint, ok := field.constraints.check(field, inst.FieldByIndex(field.structField.Index))
+		if !ok {
+			vErr := makeValidationErrorWithError(fieldKind, kind, field, nil)
+			vErr.Constraint = constraint
+			return vErr
+		}
+	}
+	return nil
+}
+
+func applyMapParam(exists bool, value map[string][]string, fieldKind fieldKind, field *requestField, inst reflect.Value) error {
+	if field.required != nil && *field.required && !exists {
+		return makeValidationError(fieldKind, ValidationErrorKindRequired, field)
+	}
+	if !exists {
+		return nil
+	}
+
+	keys := make([]string, 0, len(value))
+	for k := range value {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	t := field.structField.Type
+	m := reflect.MakeMapWithSize(t, len(value))
+	for _, k := range keys {
+		// Repeated keys use the first value, as non-slice fields do.
+		v := value[k][0]
+		if field.blank != nil && !*field.blank && strings.TrimSpace(v) == "" {
+			return makeValidationError(fieldKind, ValidationErrorKindBlank, field)
+		}
+		if field.pattern != nil && !field.pattern.MatchString(v) {
+			return makeValidationError(fieldKind, ValidationErrorKindPattern, field)
+		}
+		item := reflect.New(t.Elem()).Elem()
+		if err := field.coercer(v, item); err != nil {
+			return makeValidationErrorWithError(fieldKind, ValidationErrorKindParsing, field, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), item)
+	}
+	inst.FieldByIndex(field.structField.Index).Set(m)
+
+	kind, constraint, ok := field.constraints.check(field, m)
+	if !ok {
+		vErr := makeValidationErrorWithError(fieldKind, kind, field, nil)
+		vErr.Constraint = constraint
+		return vErr
+	}
 	return nil
 }
 
@@ -211,64 +274,133 @@ func applyFileParam(exists bool, value []*multipart.FileHeader, fieldKind fieldK
 	return nil
 }
 
+// validationCollector accumulates validation errors when collect is set.
+// Otherwise, errors are returned immediately by add.
+type validationCollector struct {
+	collect bool
+	errs    ValidationErrors
+}
+
+// add records the provided error in case it is a ValidationError and the
+// collector is collecting errors, returning nil. Any other error is returned
+// as-is, and must be returned to the caller.
+func (c *validationCollector) add(err error) error {
+	if !c.collect {
+		return err
+	}
+	if vErr, ok := err.(ValidationError); ok {
+		c.errs = append(c.errs, vErr)
+		return nil
+	}
+	return err
+}
+
+// result returns the collected errors, or nil in case none was collected.
+func (c *validationCollector) result() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
 func loadAndApplyMeta(meta *handlerMetadata, r *Request) error {
+	plan := meta.plan
 	instPtr := reflect.New(meta.structType)
 	inst := instPtr.Elem()
 	inst.FieldByIndex(meta.requestField.Index).Set(reflect.ValueOf(r))
 	httpReq := r.HTTPRequest
+	collector := validationCollector{collect: r.mux.CollectValidationErrors}
 
-	for k, v := range meta.queryParams {
-		val, exists := httpReq.URL.Query()[k]
-		if err := applyParam(exists, val, fieldKindQuery, v, inst); err != nil {
+	// Context values are usually provided by authentication middlewares, and
+	// are reported before any other field.
+	for _, s := range plan.contextValues {
+		if err := applyContextValue(r, s.field, inst); err != nil {
 			return err
 		}
 	}
 
-	{
+	if len(plan.urlParams) > 0 {
 		var routeParams chi.RouteParams
-		routeParamsLen := 0
 		if rctx := chi.RouteContext(httpReq.Context()); rctx != nil {
 			routeParams = rctx.URLParams
-			routeParamsLen = len(routeParams.Keys) - 1
 		}
 
-		for param, v := range meta.urlParams {
+		for _, s := range plan.urlParams {
 			exists := false
 			var val string
-			for k := routeParamsLen; k >= 0; k-- {
-				if routeParams.Keys[k] == param {
+			for k := 0; k < len(routeParams.Keys); k++ {
+				if routeParams.Keys[k] == s.key {
 					val = routeParams.Values[k]
 					exists = true
+					break
 				}
 			}
-			if err := applyParam(exists, []string{val}, fieldKindURLParam, v, inst); err != nil {
-				return err
+			if err := applyParam(exists, []string{val}, fieldKindURLParam, s.field, inst); err != nil {
+				if err = collector.add(err); err != nil {
+					return err
+				}
 			}
 		}
 	}
 
-	{
-		heads := r.HTTPRequest.Header
-		for param, v := range meta.headers {
-			val, exists := heads[http.CanonicalHeaderKey(param)]
-			if err := applyParam(exists, val, fieldKindHeader, v, inst); err != nil {
+	if len(plan.query) > 0 {
+		query := httpReq.URL.Query()
+		for _, s := range plan.query {
+			if err := applyValues(query, fieldKindQuery, s.field, inst); err != nil {
+				if err = collector.add(err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, s := range plan.headers {
+		val, exists := httpReq.Header[s.key]
+		if err := applyParam(exists, val, fieldKindHeader, s.field, inst); err != nil {
+			if err = collector.add(err); err != nil {
 				return err
 			}
 		}
 	}
 
+	if len(plan.cookies) > 0 {
+		cookies := httpReq.Cookies()
+		for _, s := range plan.cookies {
+			var val []string
+			for _, c := range cookies {
+				if c.Name == s.key {
+					val = append(val, c.Value)
+				}
+			}
+			if err := applyParam(val != nil, val, fieldKindCookie, s.field, inst); err != nil {
+				if err = collector.add(err); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	if meta.customParser {
 		// instance has a custom parser defined. Just invoke it.
-		customParser := inst.Addr().Interface().(CustomRequestParser)
+		customParser := instPtr.Interface().(CustomRequestParser)
 		if err := customParser.ParseRequest(r); err != nil {
-			return err
+			if err = collector.add(err); err != nil {
+				return err
+			}
 		}
 	} else if meta.body != nil {
 		err := handleBodyParsing(meta, r, inst)
-		if err != nil {
-			return makeValidationErrorWithError(fieldKindBody, ValidationErrorKindParsing, meta.body, err)
+		if err == errUnsupportedMediaType {
+			// Handled like errors returned by handlers. A new HTTPError is
+			// created for each request, so error handlers may modify it.
+			return NewHTTPError(http.StatusUnsupportedMediaType, "")
+		} else if err != nil {
+			err = makeValidationErrorWithError(fieldKindBody, ValidationErrorKindParsing, meta.body, err)
+			if err = collector.add(err); err != nil {
+				return err
+			}
 		}
-	} else if len(meta.forms) > 0 {
+	} else if len(plan.forms) > 0 {
 		err := r.HTTPRequest.ParseMultipartForm(r.maxMemory)
 		// ParseMultipartForm will fail with ErrNotMultipart when we don't have
 		// a multipart form; this is mostly fine, since it will parse the form
@@ -281,67 +413,124 @@ func loadAndApplyMeta(meta *handlerMetadata, r *Request) error {
 				// let the caller handle it.
 				return err
 			}
-			r.mux.errorHandler(err, r.httpResponse, r)
-			return nil
-
+			return err
 		} else if err == http.ErrNotMultipart {
 			isMultipart = false
 		} else if err == nil {
 			values = r.HTTPRequest.MultipartForm.Value
 		}
 
-		for k, v := range meta.forms {
+		for _, s := range plan.forms {
+			v := s.field
 			if v.fileFieldKind.IsFile() && isMultipart {
-				val, exists := r.HTTPRequest.MultipartForm.File[k]
+				val, exists := v.lookupFiles(r.HTTPRequest.MultipartForm.File)
 				if err := applyFileParam(exists, val, fieldKindForm, v, inst); err != nil {
-					return err
+					if err = collector.add(err); err != nil {
+						return err
+					}
 				}
 			} else {
-				val, exists := values[k]
-				if err := applyParam(exists, val, fieldKindForm, v, inst); err != nil {
-					return err
+				if err := applyValues(values, fieldKindForm, v, inst); err != nil {
+					if err = collector.add(err); err != nil {
+						return err
+					}
 				}
 			}
 		}
 	}
 
-	instVal := make([]reflect.Value, 1)
+	if err := collector.result(); err != nil {
+		return err
+	}
 
+	if meta.validator {
+		if err := runValidator(meta, inst); err != nil {
+			return err
+		}
+	}
+
+	arg := inst
 	if meta.wantsPtr {
-		instVal[0] = reflect.Indirect(inst).Addr()
-	} else {
-		instVal[0] = inst
-	}
-
-	var err error
-	var res reflect.Value
-	func(callArgs []reflect.Value) {
-		defer func() {
-			innerErr := recover()
-			if innerErr != nil {
-				if innerErr == errAbortRequest {
-					err = errAbortRequest
-					return
-				}
-				if e, ok := innerErr.(error); ok {
-					err = e
-				} else {
-					err = fmt.Errorf("panic called on non-error type: %s", innerErr)
-				}
-			}
-		}()
-		res = meta.handlerFunction.Call(callArgs)[0]
-	}(instVal)
+		arg = instPtr
+	}
 
+	results, err := callHandler(r, meta, arg)
 	if err != nil {
 		// We panicked earlier. Let's ignore the returned result and return our
 		// captured exception.
 		return err
 	}
 
-	if res.IsZero() || res.IsNil() {
+	res := results[len(results)-1]
+	if !res.IsNil() {
+		return res.Interface().(error)
+	}
+
+	if meta.responseType != nil && !isNilValue(results[0]) {
+		r.Respond(results[0].Interface())
+	}
+
+	return nil
+}
+
+// recoveredError converts a value recovered from a panic into an error.
+func recoveredError(recovered interface{}) error {
+	if recovered == errAbortRequest {
+		return errAbortRequest
+	}
+	if e, ok := recovered.(error); ok {
+		return e
+	}
+	return fmt.Errorf("panic called on non-error type: %s", recovered)
+}
+
+// runValidator invokes Validate on a loaded request instance. Validation errors
+// returned by it are completed with information about the fields they refer
+// to.
+func runValidator(meta *handlerMetadata, inst reflect.Value) error {
+	err := inst.Addr().Interface().(Validator).Validate()
+	if err == nil {
 		return nil
 	}
 
-	return res.Interface().(error)
+	var vErrs ValidationErrors
+	var vErr ValidationError
+	if errors.As(err, &vErrs) {
+		completed := make(ValidationErrors, len(vErrs))
+		for i, e := range vErrs {
+			completed[i] = completeValidationError(meta, e)
+		}
+		return completed
+	} else if errors.As(err, &vErr) {
+		return completeValidationError(meta, vErr)
+	}
+	return err
+}
+
+func completeValidationError(meta *handlerMetadata, err ValidationError) ValidationError {
+	if err.StructName == "" {
+		err.StructName = meta.structType.Name()
+	}
+	if field, kind, ok := meta.fieldNamed(err.FieldName); ok && err.StructFieldName == "" {
+		err.StructFieldName = field.structPath
+		if field.requestFieldName != "" {
+			err.FieldName = field.requestFieldName
+		}
+		err.FieldKind = kind
+	}
+	return err
+}
+
+func isNilValue(v reflect.Value) bool {
+	return isNillableKind(v.Kind()) && v.IsNil()
+}
+
+// isNillableKind reports whether values of a given kind can be nil.
+func isNillableKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	default:
+		return false
+	}
 }
@@ -2,11 +2,13 @@ package raggett
 
 import (
 	"bytes"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,7 +27,7 @@ func TestLoader(t *testing.T) {
 		receivedRec = req
 		return nil
 	}
-	meta, err := determineFuncParams(handler)
+	meta, err := determineFuncParams(nil, handler)
 	assert.NoError(t, err)
 	req := NewRequest(rec, httpReq)
 	err = loadAndApplyMeta(meta, req)
@@ -52,7 +54,7 @@ func TestCustomLoader(t *testing.T) {
 		receivedRec = req
 		return nil
 	}
-	meta, err := determineFuncParams(handler)
+	meta, err := determineFuncParams(nil, handler)
 	assert.NoError(t, err)
 	req := NewRequest(rec, httpReq)
 	err = loadAndApplyMeta(meta, req)
@@ -437,3 +439,212 @@ func TestDecoderError(t *testing.T) {
 		require.Equal(t, http.StatusBadRequest, resp.Code)
 	})
 }
+
+func TestCollectValidationErrors(t *testing.T) {
+	type RequestStruct struct {
+		*Request
+		Name  string `query:"name" required:"true"`
+		Age   int    `query:"age"`
+		Token string `header:"token" blank:"false"`
+	}
+
+	httpReq := httptest.NewRequest("GET", "/foo?age=abc", nil)
+	httpReq.Header.Set("token", " ")
+	called := false
+	handler := func(req RequestStruct) error {
+		called = true
+		return nil
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		meta, err := determineFuncParams(nil, handler)
+		require.NoError(t, err)
+		m := NewMux(zap.NewNop())
+		err = loadAndApplyMeta(meta, newRequest(m, httptest.NewRecorder(), httpReq))
+		require.Error(t, err)
+		assert.IsType(t, ValidationError{}, err)
+		assert.False(t, called)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		meta, err := determineFuncParams(nil, handler)
+		require.NoError(t, err)
+		m := NewMux(zap.NewNop())
+		m.CollectValidationErrors = true
+		err = loadAndApplyMeta(meta, newRequest(m, httptest.NewRecorder(), httpReq))
+		require.Error(t, err)
+		require.IsType(t, ValidationErrors{}, err)
+		errs := err.(ValidationErrors)
+		require.Len(t, errs, 3)
+
+		kinds := map[string]ValidationErrorKind{}
+		for _, e := range errs {
+			kinds[e.FieldName] = e.ErrorKind
+		}
+		assert.Equal(t, ValidationErrorKindRequired, kinds["name"])
+		assert.Equal(t, ValidationErrorKindParsing, kinds["age"])
+		assert.Equal(t, ValidationErrorKindBlank, kinds["token"])
+		assert.False(t, called)
+	})
+}
+
+func TestConstraints(t *testing.T) {
+	type RequestStruct struct {
+		*Request
+		Page    int           `query:"page" min:"1" max:"100"`
+		Ratio   *float64      `query:"ratio" min:"0" max:"1"`
+		Name    string        `query:"name" minlen:"2" maxlen:"4"`
+		Sort    string        `query:"sort" oneof:"name|date"`
+		Level   int           `query:"level" oneof:"1|2|3"`
+		Tags    []string      `query:"tag" minitems:"1" maxitems:"2" maxlen:"3"`
+		Timeout time.Duration `query:"timeout" max:"1m"`
+	}
+
+	meta, err := determineFuncParams(nil, func(req RequestStruct) error { return nil })
+	require.NoError(t, err)
+
+	apply := func(query string) error {
+		httpReq := httptest.NewRequest("GET", "/foo?"+query, nil)
+		m := NewMux(zap.NewNop())
+		return loadAndApplyMeta(meta, newRequest(m, httptest.NewRecorder(), httpReq))
+	}
+
+	assert.NoError(t, apply("page=1&ratio=0.5&name=ab&sort=date&level=03&tag=a&timeout=30s"))
+	assert.NoError(t, apply(""), "absent fields must not be validated")
+
+	cases := []struct {
+		query      string
+		field      string
+		kind       ValidationErrorKind
+		constraint string
+	}{
+		{"page=0", "page", ValidationErrorKindMin, "min=1"},
+		{"page=101", "page", ValidationErrorKindMax, "max=100"},
+		{"ratio=1.5", "ratio", ValidationErrorKindMax, "max=1"},
+		{"name=a", "name", ValidationErrorKindMinLength, "minlen=2"},
+		{"name=abcde", "name", ValidationErrorKindMaxLength, "maxlen=4"},
+		{"sort=size", "sort", ValidationErrorKindOneOf, "oneof=name|date"},
+		{"level=4", "level", ValidationErrorKindOneOf, "oneof=1|2|3"},
+		{"tag=a&tag=b&tag=c", "tag", ValidationErrorKindMaxItems, "maxitems=2"},
+		{"tag=abcd", "tag", ValidationErrorKindMaxLength, "maxlen=3"},
+		{"timeout=2m", "timeout", ValidationErrorKindMax, "max=1m"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			err := apply(c.query)
+			require.Error(t, err)
+			require.IsType(t, ValidationError{}, err)
+			vErr := err.(ValidationError)
+			assert.Equal(t, c.field, vErr.FieldName)
+			assert.Equal(t, c.kind, vErr.ErrorKind)
+			assert.Equal(t, c.constraint, vErr.Constraint)
+			assert.Contains(t, vErr.Error(), c.constraint)
+		})
+	}
+}
+
+func TestConstraintsMinItems(t *testing.T) {
+	type RequestStruct struct {
+		*Request
+		Tags []string `query:"tag" minitems:"2"`
+	}
+	meta, err := determineFuncParams(nil, func(req RequestStruct) error { return nil })
+	require.NoError(t, err)
+	httpReq := httptest.NewRequest("GET", "/foo?tag=a", nil)
+	err = loadAndApplyMeta(meta, newRequest(NewMux(zap.NewNop()), httptest.NewRecorder(), httpReq))
+	require.IsType(t, ValidationError{}, err)
+	assert.Equal(t, ValidationErrorKindMinItems, err.(ValidationError).ErrorKind)
+}
+
+type validatedRequest struct {
+	*Request
+	Start int    `query:"start"`
+	End   int    `query:"end"`
+	Email string `query:"email"`
+	Phone string `header:"X-Phone"`
+}
+
+var errValidatorFailed = fmt.Errorf("validator failed")
+
+func (v *validatedRequest) Validate() error {
+	if v.Start == -1 {
+		return errValidatorFailed
+	}
+	if v.End < v.Start {
+		return FieldError("end", fmt.Errorf("must be after start"))
+	}
+	if v.Email == "" && v.Phone == "" {
+		return ValidationErrors{
+			FieldError("Email", fmt.Errorf("either email or phone is required")),
+			FieldError("Phone", fmt.Errorf("either email or phone is required")),
+		}
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	called := false
+	handler := func(req validatedRequest) error {
+		called = true
+		return nil
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("POST", "/?start=1&end=2&email=a@b.c", nil)
+		w, validationError, runtimeError := testMuxPostWith(t, req, "/", handler)
+		assert.NoError(t, validationError)
+		assert.NoError(t, runtimeError)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.True(t, called)
+	})
+
+	t.Run("Field error", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("POST", "/?start=2&end=1&email=a@b.c", nil)
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", handler)
+		assert.NoError(t, runtimeError)
+		require.IsType(t, ValidationError{}, validationError)
+		vErr := validationError.(ValidationError)
+		assert.Equal(t, "validatedRequest", vErr.StructName)
+		assert.Equal(t, "End", vErr.StructFieldName)
+		assert.Equal(t, "end", vErr.FieldName)
+		assert.Equal(t, fieldKindQuery, vErr.FieldKind)
+		assert.Equal(t, ValidationErrorKindCustom, vErr.ErrorKind)
+		assert.Equal(t, "Validation of validatedRequest failed: Value for field end failed validation: must be after start", vErr.Error())
+		assert.False(t, called)
+	})
+
+	t.Run("Multiple errors", func(t *testing.T) {
+		called = false
+		meta, err := determineFuncParams(nil, handler)
+		require.NoError(t, err)
+		httpReq := httptest.NewRequest("POST", "/", nil)
+		err = loadAndApplyMeta(meta, newRequest(NewMux(zap.NewNop()), httptest.NewRecorder(), httpReq))
+		require.IsType(t, ValidationErrors{}, err)
+		errs := err.(ValidationErrors)
+		require.Len(t, errs, 2)
+		assert.Equal(t, "email", errs[0].FieldName)
+		assert.Equal(t, fieldKindQuery, errs[0].FieldKind)
+		assert.Equal(t, "X-Phone", errs[1].FieldName)
+		assert.Equal(t, fieldKindHeader, errs[1].FieldKind)
+		assert.False(t, called)
+
+		code, body := doRequest(func() *Mux {
+			m := NewMux(zap.NewNop())
+			m.Get("/", handler)
+			return m
+		}(), "application/json", "GET", "/", nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, body, "either email or phone is required")
+	})
+
+	t.Run("Runtime error", func(t *testing.T) {
+		called = false
+		req := httptest.NewRequest("POST", "/?start=-1", nil)
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", handler)
+		assert.NoError(t, validationError)
+		assert.ErrorIs(t, runtimeError, errValidatorFailed)
+		assert.False(t, called)
+	})
+}
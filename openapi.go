@@ -0,0 +1,529 @@
+package raggett
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+const openAPIVersion = "3.0.3"
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// OpenAPIInfo represents the Info Object of an OpenAPI document, providing
+// metadata about the API.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIDocument represents an OpenAPI 3 document describing handlers
+// registered on a Mux. Documents can be encoded using encoding/json, or
+// through the YAML method.
+type OpenAPIDocument struct {
+	OpenAPI string                     `json:"openapi"`
+	Info    OpenAPIInfo                `json:"info"`
+	Paths   map[string]OpenAPIPathItem `json:"paths"`
+}
+
+// OpenAPIPathItem maps lowercase HTTP methods to the operation handling them
+// for a given path.
+type OpenAPIPathItem map[string]*OpenAPIOperation
+
+// OpenAPIOperation describes a single API operation on a path.
+type OpenAPIOperation struct {
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a single operation parameter, obtained from a
+// `url-param`, `query`, `header`, or `cookie` field.
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Style    string         `json:"style,omitempty"`
+	Explode  *bool          `json:"explode,omitempty"`
+	Schema   *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIRequestBody describes the body expected by an operation, obtained
+// from `body` or `form` fields.
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content"`
+}
+
+// OpenAPIMediaType describes the schema of a given media type.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema,omitempty"`
+}
+
+// OpenAPIResponse describes a single response of an operation.
+type OpenAPIResponse struct {
+	Description string                       `json:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty"`
+}
+
+// OpenAPISchema represents the subset of JSON Schema used by OpenAPI documents
+// generated by Raggett.
+type OpenAPISchema struct {
+	Type                 string                    `json:"type,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Default              interface{}               `json:"default,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty"`
+	MinProperties        *int                      `json:"minProperties,omitempty"`
+	MaxProperties        *int                      `json:"maxProperties,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+}
+
+// YAML returns the YAML representation of the document.
+func (d *OpenAPIDocument) YAML() ([]byte, error) {
+	// Round-trip through JSON so json tags are honoured, and empty values
+	// are omitted consistently between both representations.
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+// OpenAPI returns an OpenAPI document describing all handlers registered on
+// this Mux, including handlers registered on sub-Muxes created through Route.
+func (mx *Mux) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    info,
+		Paths:   map[string]OpenAPIPathItem{},
+	}
+
+	for pattern, route := range mx.handlers {
+		path, pathPatterns := openAPIPath(pattern)
+		item := OpenAPIPathItem{}
+		for method, meta := range route.handlers {
+			if meta.openAPIHidden {
+				continue
+			}
+			item[strings.ToLower(method)] = openAPIOperation(meta, pathPatterns)
+		}
+		if len(item) > 0 {
+			doc.Paths[path] = item
+		}
+	}
+
+	return doc
+}
+
+// ServeOpenAPI registers a GET handler for `pattern` serving the OpenAPI
+// document describing handlers registered on this Mux. The document is
+// generated for each request, and served as JSON unless the client prefers
+// YAML. The handler itself is not described by the document.
+func (mx *Mux) ServeOpenAPI(pattern string, info OpenAPIInfo) {
+	mx.Get(pattern, func(r *EmptyRequest) error {
+		doc := mx.OpenAPI(info)
+		_, selected, media := NegotiateContentType(r.HTTPRequest, []string{"application/json", "application/yaml"})
+
+		var (
+			data []byte
+			err  error
+		)
+		if selected && media.Type() == "application/yaml" {
+			r.SetContentType("application/yaml")
+			data, err = doc.YAML()
+		} else {
+			r.SetContentType("application/json")
+			data, err = json.Marshal(doc)
+		}
+		if err != nil {
+			return makeError(err)
+		}
+		r.RespondBytes(data)
+		return nil
+	})
+	mx.handlerMetaFor("GET", mx.routePrefix+pattern).openAPIHidden = true
+}
+
+var chiParamRegexp = regexp.MustCompile(`\{([^{}:]+)(?::([^{}]+))?\}`)
+
+// openAPIPath converts a chi routing pattern into an OpenAPI path, returning
+// the converted path, and any regular expressions used by its parameters.
+func openAPIPath(pattern string) (string, map[string]string) {
+	patterns := map[string]string{}
+	path := chiParamRegexp.ReplaceAllStringFunc(pattern, func(s string) string {
+		m := chiParamRegexp.FindStringSubmatch(s)
+		if m[2] != "" {
+			patterns[m[1]] = "^" + m[2] + "$"
+		}
+		return "{" + m[1] + "}"
+	})
+	return path, patterns
+}
+
+func openAPIOperation(meta *handlerMetadata, pathPatterns map[string]string) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Responses: map[string]*OpenAPIResponse{},
+	}
+
+	// Parameters follow the order fields are loaded in, as defined by the
+	// handler's bindingPlan.
+	for _, s := range meta.plan.urlParams {
+		param := openAPIParameter("path", s.field)
+		param.Required = true
+		if p, ok := pathPatterns[s.key]; ok && param.Schema.Pattern == "" {
+			param.Schema.Pattern = p
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+	for _, s := range meta.plan.query {
+		op.Parameters = append(op.Parameters, openAPIParameter("query", s.field))
+	}
+	for _, s := range meta.plan.headers {
+		op.Parameters = append(op.Parameters, openAPIParameter("header", s.field))
+	}
+	for _, s := range meta.plan.cookies {
+		op.Parameters = append(op.Parameters, openAPIParameter("cookie", s.field))
+	}
+
+	if meta.body != nil {
+		op.RequestBody = openAPIBody(meta)
+	} else if len(meta.forms) > 0 {
+		op.RequestBody = openAPIForm(meta)
+	}
+
+	// Requests are answered with a 204 unless a response is provided, either
+	// through Request.Respond, or by returning a non-nil value.
+	success := &OpenAPIResponse{Description: "Successful response"}
+	if meta.responseType != nil {
+		success.Content = openAPIResponseContent(meta.responseType)
+	}
+	op.Responses["200"] = success
+	if meta.responseType == nil || isNillableKind(meta.responseType.Kind()) {
+		op.Responses["204"] = &OpenAPIResponse{Description: "Successful response without content"}
+	}
+	if op.Parameters != nil || op.RequestBody != nil {
+		op.Responses["400"] = &OpenAPIResponse{Description: "Validation error"}
+	}
+	op.Responses["default"] = &OpenAPIResponse{Description: "Unexpected error"}
+
+	return op
+}
+
+func openAPIParameter(in string, field *requestField) *OpenAPIParameter {
+	param := &OpenAPIParameter{
+		Name:     field.names[0],
+		In:       in,
+		Required: field.required != nil && *field.required,
+		Schema:   openAPIFieldSchema(field),
+	}
+	if field.isMap {
+		explode := true
+		param.Style = "deepObject"
+		param.Explode = &explode
+	} else if field.split == "," && in == "query" {
+		explode := false
+		param.Style = "form"
+		param.Explode = &explode
+	}
+	return param
+}
+
+// openAPIFieldSchema returns the schema for a given field, including
+// constraints defined through validation tags.
+func openAPIFieldSchema(field *requestField) *OpenAPISchema {
+	if field.fileFieldKind.IsFile() {
+		schema := &OpenAPISchema{Type: "string", Format: "binary"}
+		if field.fileFieldKind.IsSlice() {
+			schema = &OpenAPISchema{Type: "array", Items: schema}
+		}
+		return schema
+	}
+
+	schema := openAPIParameterSchema(field.structField.Type)
+	if field.isSlice {
+		schema = &OpenAPISchema{Type: "array", Items: openAPIParameterSchema(field.structField.Type.Elem())}
+	} else if field.isMap {
+		schema = &OpenAPISchema{Type: "object", AdditionalProperties: openAPIParameterSchema(field.structField.Type.Elem())}
+	}
+	target := schema
+	if field.isSlice {
+		target = schema.Items
+	} else if field.isMap {
+		target = schema.AdditionalProperties
+	}
+	if field.pattern != nil {
+		target.Pattern = field.pattern.String()
+	}
+	if field.defaultValue != nil {
+		schema.Default = openAPIDefault(field, target)
+	}
+
+	c := field.constraints
+	if target.Type == "integer" || target.Type == "number" {
+		if c.min != nil {
+			target.Minimum = c.min
+		}
+		if c.max != nil {
+			target.Maximum = c.max
+		}
+	}
+	if c.minLen != nil {
+		target.MinLength = c.minLen
+	}
+	if c.maxLen != nil {
+		target.MaxLength = c.maxLen
+	}
+	if field.isMap {
+		schema.MinProperties = c.minItems
+		schema.MaxProperties = c.maxItems
+	} else {
+		schema.MinItems = c.minItems
+		schema.MaxItems = c.maxItems
+	}
+	for i, v := range c.oneOfValues {
+		if target.Type == "string" {
+			target.Enum = append(target.Enum, c.oneOf[i])
+		} else {
+			target.Enum = append(target.Enum, v.Interface())
+		}
+	}
+	if field.blank != nil && !*field.blank && target.Type == "string" {
+		// blank:"false" rejects values made of whitespace only, which
+		// minLength alone does not express.
+		if target.MinLength == nil || *target.MinLength < 1 {
+			minLength := 1
+			target.MinLength = &minLength
+		}
+		if target.Pattern == "" {
+			target.Pattern = `\S`
+		} else {
+			target.Description = "Must not be blank."
+		}
+	}
+	return schema
+}
+
+// openAPIDefault returns the default value of a given field, as represented
+// by encoding/json.
+func openAPIDefault(field *requestField, schema *OpenAPISchema) interface{} {
+	t := field.structField.Type
+	if field.isSlice {
+		t = t.Elem()
+	}
+	values := make([]interface{}, 0, len(field.defaultValue))
+	for _, raw := range field.defaultValue {
+		var value interface{} = raw
+		if schema.Type != "string" {
+			v := reflect.New(t).Elem()
+			if err := field.coercer(raw, v); err != nil {
+				return nil
+			}
+			value = v.Interface()
+		}
+		values = append(values, value)
+	}
+	if field.isSlice {
+		return values
+	}
+	return values[0]
+}
+
+func openAPIBody(meta *handlerMetadata) *OpenAPIRequestBody {
+	schema := openAPISchemaForType(meta.body.structField.Type, map[reflect.Type]bool{})
+	mediaTypes := meta.bodyParser.mediaTypes
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{"*/*"}
+	}
+
+	body := &OpenAPIRequestBody{
+		Required: meta.body.required != nil && *meta.body.required,
+		Content:  map[string]*OpenAPIMediaType{},
+	}
+	for _, m := range mediaTypes {
+		body.Content[m] = &OpenAPIMediaType{Schema: schema}
+	}
+	return body
+}
+
+func openAPIForm(meta *handlerMetadata) *OpenAPIRequestBody {
+	schema := &OpenAPISchema{
+		Type:       "object",
+		Properties: map[string]*OpenAPISchema{},
+	}
+	hasFiles := false
+	for _, s := range meta.plan.forms {
+		field := s.field
+		hasFiles = hasFiles || field.fileFieldKind.IsFile()
+		schema.Properties[field.names[0]] = openAPIFieldSchema(field)
+		if field.required != nil && *field.required {
+			schema.Required = append(schema.Required, field.names[0])
+		}
+	}
+
+	body := &OpenAPIRequestBody{
+		Required: len(schema.Required) > 0,
+		Content: map[string]*OpenAPIMediaType{
+			"multipart/form-data": {Schema: schema},
+		},
+	}
+	if !hasFiles {
+		body.Content["application/x-www-form-urlencoded"] = &OpenAPIMediaType{Schema: schema}
+	}
+	return body
+}
+
+func openAPIResponseContent(t reflect.Type) map[string]*OpenAPIMediaType {
+	content := map[string]*OpenAPIMediaType{}
+	responders := []struct {
+		iface     reflect.Type
+		mediaType MediaType
+		schema    *OpenAPISchema
+	}{
+		{reflect.TypeOf((*HTMLResponder)(nil)).Elem(), htmlContentType, &OpenAPISchema{Type: "string"}},
+		{reflect.TypeOf((*JSONResponder)(nil)).Elem(), jsonContentType, nil},
+		{reflect.TypeOf((*XMLResponder)(nil)).Elem(), xmlContentType, nil},
+		{reflect.TypeOf((*BytesResponder)(nil)).Elem(), bytesContentType, &OpenAPISchema{Type: "string", Format: "binary"}},
+		{reflect.TypeOf((*PlainTextResponder)(nil)).Elem(), plainTextContentType, &OpenAPISchema{Type: "string"}},
+	}
+	for _, r := range responders {
+		if t.Implements(r.iface) {
+			content[r.mediaType.Type()] = &OpenAPIMediaType{Schema: r.schema}
+		}
+	}
+	if len(content) == 0 {
+		return nil
+	}
+	return content
+}
+
+// openAPIParameterSchema maps types parsed from strings, such as query and
+// header values, into their JSON Schema representation.
+func openAPIParameterSchema(t reflect.Type) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr && t != urlPtrType {
+		t = t.Elem()
+	}
+	switch t {
+	case durationType:
+		return &OpenAPISchema{Type: "string", Format: "duration"}
+	case urlPtrType:
+		return &OpenAPISchema{Type: "string", Format: "uri"}
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) && !reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &OpenAPISchema{Type: "string"}
+	}
+	return openAPISchemaForType(t, map[reflect.Type]bool{})
+}
+
+// openAPISchemaForType maps a Go type into its JSON Schema representation.
+// Struct fields are named after their json tags, when present.
+func openAPISchemaForType(t reflect.Type, visited map[reflect.Type]bool) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case t == uuidType:
+		return &OpenAPISchema{Type: "string", Format: "uuid"}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		// encoding/json represents those types as strings.
+		return &OpenAPISchema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		// int is described as int64, since it is 64 bits wide on 64-bit
+		// platforms.
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := float64(0)
+		format := "int32"
+		if t.Kind() == reflect.Uint64 || t.Kind() == reflect.Uint {
+			format = "int64"
+		}
+		return &OpenAPISchema{Type: "integer", Format: format, Minimum: &minimum}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "binary"}
+		}
+		return &OpenAPISchema{Type: "array", Items: openAPISchemaForType(t.Elem(), visited)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: openAPISchemaForType(t.Elem(), visited)}
+	case reflect.Struct:
+		if visited[t] {
+			// Recursive types are represented as generic objects.
+			return &OpenAPISchema{Type: "object"}
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+		openAPIStructProperties(t, schema, visited)
+		return schema
+	default:
+		return &OpenAPISchema{}
+	}
+}
+
+func openAPIStructProperties(t reflect.Type, schema *OpenAPISchema, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		} else if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				openAPIStructProperties(ft, schema, visited)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported field
+			continue
+		}
+		schema.Properties[name] = openAPISchemaForType(f.Type, visited)
+	}
+}
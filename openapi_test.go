@@ -0,0 +1,243 @@
+package raggett
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+type openAPIUser struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Secret  string   `json:"-"`
+	Friends []openAPIUser
+}
+
+func (u openAPIUser) JSON() interface{} { return u }
+
+func TestOpenAPI(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Route("/users", func(r *Mux) {
+		r.Get("/{id:[0-9]+}", func(r *struct {
+			*Request
+			ID      int    `url-param:"id"`
+			Verbose bool   `query:"verbose" default:"true"`
+			Token   string `header:"X-Token" required:"true" blank:"false"`
+		}) (openAPIUser, error) {
+			return openAPIUser{}, nil
+		})
+		r.Post("/", func(r *struct {
+			*Request
+			User openAPIUser `body:"json"`
+		}) error {
+			return nil
+		})
+		r.Put("/{id}/avatar", func(r *struct {
+			*Request
+			ID     string                  `url-param:"id" pattern:"^[a-z]+$"`
+			Avatar *multipart.FileHeader   `form:"avatar" required:"true"`
+			Extra  []*multipart.FileHeader `form:"extra"`
+		}) error {
+			return nil
+		})
+	})
+
+	doc := mx.OpenAPI(OpenAPIInfo{Title: "Test", Version: "1.0"})
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "Test", doc.Info.Title)
+
+	get := doc.Paths["/users/{id}"]["get"]
+	require.NotNil(t, get)
+	require.Len(t, get.Parameters, 3)
+	assert.Equal(t, "id", get.Parameters[0].Name)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.True(t, get.Parameters[0].Required)
+	assert.Equal(t, "integer", get.Parameters[0].Schema.Type)
+	assert.Equal(t, "int64", get.Parameters[0].Schema.Format)
+	assert.Equal(t, "^[0-9]+$", get.Parameters[0].Schema.Pattern)
+	assert.Equal(t, "verbose", get.Parameters[1].Name)
+	assert.Equal(t, "query", get.Parameters[1].In)
+	assert.False(t, get.Parameters[1].Required)
+	assert.Equal(t, "boolean", get.Parameters[1].Schema.Type)
+	assert.Equal(t, true, get.Parameters[1].Schema.Default)
+	assert.Equal(t, "X-Token", get.Parameters[2].Name)
+	assert.Equal(t, "header", get.Parameters[2].In)
+	assert.True(t, get.Parameters[2].Required)
+	assert.Equal(t, 1, *get.Parameters[2].Schema.MinLength)
+	assert.Equal(t, `\S`, get.Parameters[2].Schema.Pattern)
+
+	success := get.Responses["200"].Content["application/json"]
+	require.NotNil(t, success)
+	assert.NotContains(t, get.Responses, "204")
+	assert.Contains(t, get.Responses, "400")
+	assert.Contains(t, get.Responses, "default")
+
+	post := doc.Paths["/users/"]["post"]
+	require.NotNil(t, post)
+	assert.Contains(t, post.Responses, "204")
+	require.NotNil(t, post.RequestBody)
+	body := post.RequestBody.Content["application/json"]
+	require.NotNil(t, body)
+	assert.Equal(t, "object", body.Schema.Type)
+	assert.Equal(t, "integer", body.Schema.Properties["id"].Type)
+	assert.Equal(t, "int64", body.Schema.Properties["id"].Format)
+	assert.Equal(t, "array", body.Schema.Properties["tags"].Type)
+	assert.Equal(t, "string", body.Schema.Properties["tags"].Items.Type)
+	assert.NotContains(t, body.Schema.Properties, "Secret")
+	assert.Equal(t, "object", body.Schema.Properties["Friends"].Items.Type)
+
+	put := doc.Paths["/users/{id}/avatar"]["put"]
+	require.NotNil(t, put)
+	assert.Equal(t, "^[a-z]+$", put.Parameters[0].Schema.Pattern)
+	require.NotNil(t, put.RequestBody)
+	assert.True(t, put.RequestBody.Required)
+	assert.NotContains(t, put.RequestBody.Content, "application/x-www-form-urlencoded")
+	form := put.RequestBody.Content["multipart/form-data"]
+	require.NotNil(t, form)
+	assert.Equal(t, []string{"avatar"}, form.Schema.Required)
+	assert.Equal(t, "string", form.Schema.Properties["avatar"].Type)
+	assert.Equal(t, "binary", form.Schema.Properties["avatar"].Format)
+	assert.Equal(t, "array", form.Schema.Properties["extra"].Type)
+	assert.Equal(t, "binary", form.Schema.Properties["extra"].Items.Format)
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/hello", func(r *struct {
+		*Request
+		Name string `query:"name"`
+	}) error {
+		return nil
+	})
+	mx.ServeOpenAPI("/openapi", OpenAPIInfo{Title: "Test", Version: "1.0"})
+
+	req := httptest.NewRequest("GET", "/openapi", nil)
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Contains(t, doc["paths"], "/hello")
+	assert.NotContains(t, doc["paths"], "/openapi")
+
+	req = httptest.NewRequest("GET", "/openapi", nil)
+	req.Header.Set("Accept", "application/yaml")
+	w = httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+	doc = nil
+	require.NoError(t, yaml.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.0.3", doc["openapi"])
+
+	req = httptest.NewRequest("GET", "/openapi", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.NotEmpty(t, w.Header().Get("Request-ID"))
+
+	assert.NotNil(t, mx.handlerMetaFor("GET", "/openapi"))
+}
+
+func TestOpenAPIConstraints(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r *struct {
+		*Request
+		Limit int      `query:"limit" min:"1" max:"50"`
+		Sort  string   `query:"sort" oneof:"name|date" maxlen:"4"`
+		Tags  []string `query:"tag" minitems:"1" maxitems:"3" minlen:"2"`
+		Code  string   `query:"code" minlen:"5" blank:"false"`
+		Slug  string   `query:"slug" pattern:"^[a-z ]+$" blank:"false"`
+		Name  string   `query:"name" blank:"false"`
+	}) error {
+		return nil
+	})
+
+	op := mx.OpenAPI(OpenAPIInfo{}).Paths["/"]["get"]
+	require.Len(t, op.Parameters, 6)
+	limit, sort, tags := op.Parameters[0].Schema, op.Parameters[1].Schema, op.Parameters[2].Schema
+	code, slug, name := op.Parameters[3].Schema, op.Parameters[4].Schema, op.Parameters[5].Schema
+	assert.Equal(t, 1.0, *limit.Minimum)
+	assert.Equal(t, 50.0, *limit.Maximum)
+	assert.Equal(t, []interface{}{"name", "date"}, sort.Enum)
+	assert.Equal(t, 4, *sort.MaxLength)
+	assert.Equal(t, 1, *tags.MinItems)
+	assert.Equal(t, 3, *tags.MaxItems)
+	assert.Equal(t, 2, *tags.Items.MinLength)
+	assert.Equal(t, 5, *code.MinLength)
+	assert.Equal(t, 1, *name.MinLength)
+	assert.Equal(t, `\S`, name.Pattern)
+	assert.Equal(t, "^[a-z ]+$", slug.Pattern)
+	assert.Equal(t, "Must not be blank.", slug.Description)
+}
+
+func TestOpenAPIOptionalResponse(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r *EmptyRequest) (*openAPIUser, error) {
+		return nil, nil
+	})
+
+	op := mx.OpenAPI(OpenAPIInfo{}).Paths["/"]["get"]
+	assert.NotNil(t, op.Responses["200"].Content["application/json"])
+	assert.Contains(t, op.Responses, "204")
+	assert.NotContains(t, op.Responses, "400")
+}
+
+func TestOpenAPIDeclarationOrder(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Post("/", func(r *struct {
+		*Request
+		Zeta  string `query:"zeta"`
+		Alpha string `query:"alpha"`
+		Last  string `form:"last" required:"true"`
+		First string `form:"first" required:"true"`
+	}) error {
+		return nil
+	})
+
+	op := mx.OpenAPI(OpenAPIInfo{}).Paths["/"]["post"]
+	require.Len(t, op.Parameters, 2)
+	assert.Equal(t, "zeta", op.Parameters[0].Name)
+	assert.Equal(t, "alpha", op.Parameters[1].Name)
+	assert.Equal(t, []string{"last", "first"}, op.RequestBody.Content["multipart/form-data"].Schema.Required)
+}
+
+func TestOpenAPIBracketNotation(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r *struct {
+		*Request
+		IDs    []int             `query:"ids" split:"," default:"1,2"`
+		Meta   map[string]string `query:"meta" maxitems:"5"`
+		Filter struct {
+			Status string `query:"status"`
+		} `query:"filter"`
+	}) error {
+		return nil
+	})
+
+	op := mx.OpenAPI(OpenAPIInfo{}).Paths["/"]["get"]
+	require.Len(t, op.Parameters, 3)
+	ids, meta, status := op.Parameters[0], op.Parameters[1], op.Parameters[2]
+
+	assert.Equal(t, "filter[status]", status.Name)
+
+	assert.Equal(t, "form", ids.Style)
+	assert.False(t, *ids.Explode)
+	assert.Equal(t, []interface{}{1, 2}, ids.Schema.Default)
+
+	assert.Equal(t, "deepObject", meta.Style)
+	assert.True(t, *meta.Explode)
+	assert.Equal(t, "object", meta.Schema.Type)
+	assert.Equal(t, "string", meta.Schema.AdditionalProperties.Type)
+	assert.Equal(t, 5, *meta.Schema.MaxProperties)
+}
@@ -0,0 +1,91 @@
+package raggett
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// bindingStep represents a single field loaded by a bindingPlan, along with
+// the key used to look up its values.
+type bindingStep struct {
+	field *requestField
+	// key is the name of the URL parameter, canonical header or cookie
+	// providing values to the field.
+	key string
+}
+
+// bindingPlan contains the steps required to load a request struct, compiled
+// once when a handler is registered, so loading requests does not require
+// inspecting handlerMetadata maps.
+// Fields are loaded, and therefore validated, in the following resolver
+// order: context, url-param, query, header, cookie, and finally body or form.
+// Fields using the same resolver are processed in struct declaration order,
+// with fields of embedded and nested structs placed where the struct is
+// declared.
+// Plans only remove the cost of inspecting metadata on every request. Request
+// structs are still allocated and filled through reflection, and handlers are
+// invoked through reflect.Value.Call, which allocates the argument and result
+// slices on each call, since handler types are only known at runtime.
+type bindingPlan struct {
+	contextValues []bindingStep
+	urlParams     []bindingStep
+	query         []bindingStep
+	headers       []bindingStep
+	cookies       []bindingStep
+	forms         []bindingStep
+}
+
+// compileBindingPlan creates a bindingPlan for a given handlerMetadata. Must be
+// called after all fields are loaded.
+func compileBindingPlan(meta *handlerMetadata) *bindingPlan {
+	same := func(name string) string { return name }
+
+	return &bindingPlan{
+		contextValues: bindingSteps(meta.contextValues, same),
+		urlParams:     bindingSteps(meta.urlParams, same),
+		query:         bindingSteps(meta.queryParams, same),
+		headers:       bindingSteps(meta.headers, http.CanonicalHeaderKey),
+		cookies:       bindingSteps(meta.cookies, same),
+		forms:         bindingSteps(meta.forms, same),
+	}
+}
+
+// bindingSteps returns steps for a given set of fields in struct declaration
+// order, using key to obtain the lookup key of each field from its name.
+func bindingSteps(fields map[string]*requestField, key func(string) string) []bindingStep {
+	result := make([]bindingStep, 0, len(fields))
+	for name, f := range fields {
+		result = append(result, bindingStep{field: f, key: key(name)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return indexLess(result[i].field.structField.Index, result[j].field.structField.Index)
+	})
+	return result
+}
+
+// indexLess reports whether a field with index sequence a is declared before
+// a field with index sequence b.
+func indexLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// callHandler invokes the handler function with the loaded request instance,
+// converting panics into errors. The stack trace of panics other than the ones
+// raised by Request.Abort and Request.NotFound is kept in r.panicStack.
+func callHandler(r *Request, meta *handlerMetadata, arg reflect.Value) (results []reflect.Value, err error) {
+	defer func() {
+		if innerErr := recover(); innerErr != nil {
+			err = recoveredError(innerErr)
+			if err != errAbortRequest && !r.isAbortNotFound(err) {
+				r.panicStack = getStack(2)
+			}
+		}
+	}()
+	return meta.handlerFunction.Call([]reflect.Value{arg}), nil
+}
@@ -0,0 +1,269 @@
+package raggett
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+var (
+	problemJSONContentType = MediaTypeFromString("application", "problem+json")
+	problemXMLContentType  = MediaTypeFromString("application", "problem+xml")
+
+	// problemOffers lists media types accepted by clients that can be served
+	// by a Problem. Plain JSON and XML are served using their problem
+	// counterparts.
+	problemOffers = []MediaType{
+		problemJSONContentType,
+		problemXMLContentType,
+		jsonContentType,
+		MediaTypeFromString("application", "xml"),
+		xmlContentType,
+	}
+)
+
+// problemXMLNamespace is the namespace used by XML representations of
+// Problem Details objects.
+const problemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem represents a Problem Details object, as defined by RFC 9457.
+// Handlers may return a *Problem as an error, in which case it is sent to the
+// client as application/problem+json or application/problem+xml, depending
+// on the client's Accept header. Problems are also used by the default error
+// handlers when Mux.ProblemDetails is set.
+type Problem struct {
+	// Type is a URI reference identifying the problem type. Defaults to
+	// "about:blank".
+	Type string
+	// Title is a short, human-readable summary of the problem type. Defaults
+	// to the text of Status.
+	Title string
+	// Status is the HTTP status code of the response. Defaults to 500.
+	Status int
+	// Detail is a human-readable explanation specific to this occurrence of
+	// the problem.
+	Detail string
+	// Instance identifies this occurrence of the problem. Defaults to the
+	// request's identifier.
+	Instance string
+	// Extensions contains additional members of the problem. Members named
+	// after the ones above are ignored.
+	Extensions map[string]interface{}
+}
+
+// NewProblem returns a new Problem with the provided status and detail.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Status: status, Detail: detail}
+}
+
+// With sets an extension member of the problem, returning the problem itself.
+func (p *Problem) With(name string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[name] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	title := p.Title
+	if title == "" {
+		title = http.StatusText(p.status())
+	}
+	if p.Detail == "" {
+		return title
+	}
+	return title + ": " + p.Detail
+}
+
+func (p *Problem) status() int {
+	if p.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+// complete returns a copy of the problem with defaults applied for a given
+// request.
+func (p *Problem) complete(r *Request) *Problem {
+	c := *p
+	c.Status = p.status()
+	if c.Type == "" {
+		c.Type = "about:blank"
+	}
+	if c.Title == "" {
+		c.Title = http.StatusText(c.Status)
+	}
+	if c.Instance == "" {
+		c.Instance = r.requestID
+	}
+	return &c
+}
+
+// members returns the names of the problem's standard members, followed by
+// extensions in alphabetical order, along with their values.
+func (p *Problem) members() ([]string, map[string]interface{}) {
+	standard := []struct {
+		name  string
+		value interface{}
+		set   bool
+	}{
+		{"type", p.Type, p.Type != ""},
+		{"title", p.Title, p.Title != ""},
+		{"status", p.Status, p.Status != 0},
+		{"detail", p.Detail, p.Detail != ""},
+		{"instance", p.Instance, p.Instance != ""},
+	}
+
+	values := map[string]interface{}{}
+	var names []string
+	for _, s := range standard {
+		if s.set {
+			names = append(names, s.name)
+			values[s.name] = s.value
+		}
+	}
+
+	var extensions []string
+	for k, v := range p.Extensions {
+		switch k {
+		case "type", "title", "status", "detail", "instance":
+			continue
+		}
+		extensions = append(extensions, k)
+		values[k] = v
+	}
+	sort.Strings(extensions)
+	return append(names, extensions...), values
+}
+
+// MarshalJSON encodes the problem as a JSON object, with extensions as
+// top-level members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	names, values := p.members()
+	buf := []byte{'{'}
+	for i, name := range names {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(values[name])
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = append(buf, value...)
+	}
+	return append(buf, '}'), nil
+}
+
+// MarshalXML encodes the problem as described by RFC 9457, Appendix B. Items
+// of slice extensions are encoded as <i> elements.
+func (p *Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Space: problemXMLNamespace, Local: "problem"}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	names, values := p.members()
+	for _, name := range names {
+		if err := encodeProblemXMLMember(e, name, values[name]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeProblemXMLMember(e *xml.Encoder, name string, value interface{}) error {
+	el := xml.StartElement{Name: xml.Name{Local: name}}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return e.EncodeElement(value, el)
+	}
+
+	if err := e.EncodeToken(el); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := e.EncodeElement(v.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: "i"}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(el.End())
+}
+
+// asProblem returns the Problem wrapped by a given error, if any.
+func asProblem(err error) (*Problem, bool) {
+	var p *Problem
+	if errors.As(err, &p) && p != nil {
+		return p, true
+	}
+	return nil, false
+}
+
+// writeProblem responds to the request with a given problem, using the
+// representation accepted by the client.
+func writeProblem(r *Request, p *Problem) {
+	p = p.complete(r)
+	r.SetStatus(p.Status)
+	if !r.bodyAllowedForStatus() {
+		return
+	}
+
+	_, _, media := NegotiateContentTypeWithMediaTypes(r.HTTPRequest, problemOffers)
+	isXML := strings.HasSuffix(media.SubTypeString, "xml")
+	contentType := problemJSONContentType
+	if isXML {
+		contentType = problemXMLContentType
+	}
+
+	w := r.httpResponse
+	w.Header().Set("Content-Type", contentType.String())
+	r.flushHeaders()
+
+	var err error
+	if isXML {
+		err = xml.NewEncoder(w).Encode(p)
+	} else {
+		err = json.NewEncoder(w).Encode(p)
+	}
+	if err != nil {
+		r.Logger.Error("Error encoding Problem Details payload to response", zap.Error(err))
+		r.AbortError(err)
+	}
+}
+
+// problemFieldError describes a single ValidationError as a member of the
+// "errors" extension of validation problems.
+type problemFieldError struct {
+	Field      string `json:"field,omitempty" xml:"field,omitempty"`
+	Source     string `json:"source,omitempty" xml:"source,omitempty"`
+	Kind       string `json:"kind,omitempty" xml:"kind,omitempty"`
+	Constraint string `json:"constraint,omitempty" xml:"constraint,omitempty"`
+	Message    string `json:"message" xml:"message"`
+}
+
+// validationProblem returns the Problem describing a set of validation
+// errors.
+func validationProblem(errs ValidationErrors, status int) *Problem {
+	fields := make([]problemFieldError, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, problemFieldError{
+			Field:      e.FieldName,
+			Source:     e.FieldKind.String(),
+			Kind:       e.ErrorKind.Name(),
+			Constraint: e.Constraint,
+			Message:    e.Error(),
+		})
+	}
+	return NewProblem(status, errs.Error()).With("errors", fields)
+}
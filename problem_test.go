@@ -0,0 +1,192 @@
+package raggett
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProblemEncoding(t *testing.T) {
+	p := NewProblem(http.StatusForbidden, "Your balance is too low.").
+		With("balance", 30).
+		With("accounts", []string{"/account/1", "/account/2"}).
+		With("status", 200)
+	p.Type = "https://example.com/probs/out-of-credit"
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"https://example.com/probs/out-of-credit","status":403,"detail":"Your balance is too low.","accounts":["/account/1","/account/2"],"balance":30}`, string(data))
+
+	data, err = xml.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><type>https://example.com/probs/out-of-credit</type><status>403</status><detail>Your balance is too low.</detail><accounts><i>/account/1</i><i>/account/2</i></accounts><balance>30</balance></problem>`, string(data))
+
+	assert.Equal(t, "Forbidden: Your balance is too low.", p.Error())
+}
+
+func doProblemRequest(mx *Mux, accept, method, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Accept", accept)
+	r.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, r)
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestProblemDetails(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.ProblemDetails = true
+	mx.RequestIdentifierGenerator(func(r *http.Request) string { return r.Header.Get("X-Request-ID") })
+	mx.Get("/fail", func(r *struct{ *Request }) error {
+		return fmt.Errorf("boom")
+	})
+	mx.Get("/items", func(r *struct {
+		*Request
+		Page int `query:"page" min:"1"`
+	}) error {
+		return nil
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		w, body := doProblemRequest(mx, "application/json", "GET", "/missing")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "about:blank", body["type"])
+		assert.Equal(t, "Not Found", body["title"])
+		assert.Equal(t, float64(404), body["status"])
+		assert.Equal(t, "The requested resource was not found.", body["detail"])
+		assert.Equal(t, "req-1", body["instance"])
+	})
+
+	t.Run("Method Not Allowed", func(t *testing.T) {
+		w, body := doProblemRequest(mx, "*/*", "POST", "/fail")
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Equal(t, float64(405), body["status"])
+	})
+
+	t.Run("Runtime Error", func(t *testing.T) {
+		w, body := doProblemRequest(mx, "application/problem+json", "GET", "/fail")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, "Internal Server Error", body["title"])
+		assert.NotContains(t, body, "detail")
+
+		mx.Development = true
+		defer func() { mx.Development = false }()
+		_, body = doProblemRequest(mx, "application/problem+json", "GET", "/fail")
+		assert.Equal(t, "boom", body["detail"])
+	})
+
+	t.Run("Validation Error", func(t *testing.T) {
+		w, body := doProblemRequest(mx, "application/json", "GET", "/items?page=0")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "Bad Request", body["title"])
+		require.Len(t, body["errors"], 1)
+		detail := body["errors"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "page", detail["field"])
+		assert.Equal(t, "Query String", detail["source"])
+		assert.Equal(t, "ValidationErrorKindMin", detail["kind"])
+		assert.Equal(t, "min=1", detail["constraint"])
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		w, _ := doProblemRequest(mx, "text/xml", "GET", "/items?page=0")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "application/problem+xml", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Bad Request</title><status>400</status>`)
+		assert.Contains(t, w.Body.String(), `<errors><i><field>page</field>`)
+	})
+}
+
+func TestProblemReturnedFromHandler(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r *struct{ *Request }) error {
+		return NewProblem(http.StatusConflict, "Item already exists.").With("id", "10")
+	})
+
+	w, body := doProblemRequest(mx, "application/json", "GET", "/")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Conflict", body["title"])
+	assert.Equal(t, "Item already exists.", body["detail"])
+	assert.Equal(t, "10", body["id"])
+}
+
+func TestProblemDetailsSubRouter(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/fail", func(r *struct{ *Request }) error {
+		return fmt.Errorf("boom")
+	})
+	mx.Route("/api", func(r *Mux) {
+		r.ProblemDetails = true
+		r.Get("/fail", func(r *struct{ *Request }) error {
+			return fmt.Errorf("boom")
+		})
+		r.Get("/items", func(r *struct {
+			*Request
+			Page int `query:"page" min:"1"`
+		}) error {
+			return nil
+		})
+	})
+
+	w, body := doProblemRequest(mx, "application/json", "GET", "/api/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, float64(404), body["status"])
+
+	w, body = doProblemRequest(mx, "application/json", "GET", "/api/fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Equal(t, float64(500), body["status"])
+
+	w, _ = doProblemRequest(mx, "application/json", "GET", "/api/items?page=0")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+	w, _ = doProblemRequest(mx, "application/json", "GET", "/fail")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json;charset=utf-8", w.Header().Get("Content-Type"))
+
+	w, _ = doProblemRequest(mx, "application/json", "GET", "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json;charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestProblemDetailsUnsupportedMediaType(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.ProblemDetails = true
+	mx.Post("/", func(r *struct {
+		*Request
+		Data struct {
+			Name string `json:"name"`
+		} `body:"auto"`
+	}) error {
+		return nil
+	})
+
+	for _, contentType := range []string{"text/csv", ""} {
+		r := httptest.NewRequest("POST", "/", strings.NewReader("name"))
+		r.Header.Set("Content-Type", contentType)
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, r)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Equal(t, "Unsupported Media Type", body["title"])
+		assert.Equal(t, float64(415), body["status"])
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/heyvito/raggett/templates"
 )
 
 // FileHeader is a convenience type for multipart.FileHeader. When using
@@ -24,6 +26,16 @@ type CustomRequestParser interface {
 	ParseRequest(r *Request) error
 }
 
+// Validator can be implemented by request structs requiring validations
+// involving more than one field. Validate is invoked once all fields are
+// successfully loaded, before the handler is invoked. Returning a
+// ValidationError (such as the ones created by FieldError) or
+// ValidationErrors causes the request to be handled by the validation error
+// handler. Other errors are handled as runtime errors.
+type Validator interface {
+	Validate() error
+}
+
 type fieldKind int
 
 const (
@@ -32,6 +44,9 @@ const (
 	fieldKindForm
 	fieldKindHeader
 	fieldKindBody
+	fieldKindStruct
+	fieldKindCookie
+	fieldKindContext
 )
 
 func (f fieldKind) String() string {
@@ -46,6 +61,12 @@ func (f fieldKind) String() string {
 		return "Header"
 	case fieldKindBody:
 		return "Body"
+	case fieldKindStruct:
+		return "Struct"
+	case fieldKindCookie:
+		return "Cookie"
+	case fieldKindContext:
+		return "Context"
 	default:
 		return "Unknown"
 	}
@@ -64,6 +85,11 @@ type ErrorHandlerFunc func(err error, w http.ResponseWriter, r *Request)
 // to provide custom error messages to clients.
 type ValidationErrorHandlerFunc func(err ValidationError, w http.ResponseWriter, r *Request)
 
+// ValidationErrorsHandlerFunc represents a function responsible for handling
+// a set of validation errors collected before a handler is invoked. It is only
+// used when Mux.CollectValidationErrors is set.
+type ValidationErrorsHandlerFunc func(errs ValidationErrors, w http.ResponseWriter, r *Request)
+
 // RequestIdentifierGenerator represents a function that returns a unique
 // identifier for requests handled by the Mux.
 type RequestIdentifierGenerator func(*http.Request) string
@@ -81,11 +107,18 @@ type Mux struct {
 	internalMux             *chi.Mux
 	errorHandler            ErrorHandlerFunc
 	validationErrorHandler  ValidationErrorHandlerFunc
+	validationErrorsHandler ValidationErrorsHandlerFunc
 	notFoundHandler         http.HandlerFunc
 	methodNotAllowedHandler http.HandlerFunc
 	identifierGenerator     RequestIdentifierGenerator
 	logger                  *zap.Logger
 	handlers                map[string]*routeHandler
+	routePrefix             string
+	bodyParsers             map[string]bodyParser
+	errorMappings           []errorMapping
+	parent                  *Mux
+	errorTemplates          map[string]templates.Executor
+	errorReports            *errorReportQueue
 
 	// Development defines whether the application is running in a development
 	// environment. When set to true, error responses generated by Raggett will
@@ -94,6 +127,39 @@ type Mux struct {
 	// since it may expose sensitive information for third-party.
 	Development bool
 
+	// CollectValidationErrors defines whether all validation errors for a
+	// request must be collected before responding to the client. When set to
+	// true, every field is validated and failures are reported at once through
+	// a ValidationErrors value, passed to the handler set by
+	// HandleValidationErrors. Otherwise, the first failure is reported through
+	// the handler set by HandleValidationError.
+	CollectValidationErrors bool
+
+	// StrictContentNegotiation defines whether responses provided through
+	// Request.Respond must match the Accept header provided by the client.
+	// When set to true, clients providing a malformed Accept header receive a
+	// 400, and clients not accepting any of the representations available for
+	// a response receive a 406 listing them. Otherwise, the first
+	// non-vendored representation is used. This can be overridden on a
+	// per-request basis through Request.SetStrictContentNegotiation.
+	StrictContentNegotiation bool
+
+	// ProblemDetails defines whether the default error handlers respond using
+	// Problem Details objects, as defined by RFC 9457. When set to true,
+	// runtime errors, validation errors, and requests to unknown routes or
+	// methods are answered with application/problem+json or
+	// application/problem+xml, depending on the client's Accept header.
+	// Details about runtime errors are only included when Development is set.
+	// Handlers returning a *Problem are answered this way regardless of this
+	// option.
+	ProblemDetails bool
+
+	// ContextValueErrorStatus defines the HTTP status used to respond to
+	// requests missing a value required by a field using the `context` tag, or
+	// providing a value of an unexpected type. The default value for this
+	// parameter is 401 (Unauthorized).
+	ContextValueErrorStatus int
+
 	// MaxMemory defines the max memory allowed to be consumed for files on
 	// a per-request basis. Files greater than this value will automatically be
 	// flushed to a temporary location. The default value for this parameter is
@@ -107,12 +173,13 @@ func NewMux(logger *zap.Logger) *Mux {
 		logger:              logger,
 		MaxMemory:           defaultMaxMemory,
 		handlers:            map[string]*routeHandler{},
+
+		ContextValueErrorStatus: http.StatusUnauthorized,
 	}
 	mx.internalMux = chi.NewMux()
-	mx.errorHandler = mx.defaultRuntimeErrorHandler
-	mx.validationErrorHandler = mx.defaultValidationErrorHandler
-	mx.notFoundHandler = mx.defaultNotFoundHandler
-	mx.methodNotAllowedHandler = mx.defaultMethodNotAllowedHandler
+	mx.errorHandler = defaultRuntimeErrorHandler
+	mx.validationErrorHandler = defaultValidationErrorHandler
+	mx.validationErrorsHandler = defaultValidationErrorsHandler
 
 	mx.internalMux.Use(mx.muxContextInjector)
 	mx.internalMux.Use(mx.requestLogger)
@@ -121,15 +188,29 @@ func NewMux(logger *zap.Logger) *Mux {
 	return mx
 }
 
+// muxContextInjector assigns an identifier to incoming requests. Requests
+// already identified by another Mux, as happens with mounted Muxes, keep their
+// identifier.
 func (mx *Mux) muxContextInjector(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(requestIDContextKey).(string); ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
 		ctx := context.WithValue(r.Context(), requestIDContextKey, mx.identifierGenerator(r))
 		handler.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// requestLogger logs the beginning and end of requests. Requests already
+// logged by another Mux are not logged again.
 func (mx *Mux) requestLogger(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(requestLoggerContextKey) != nil {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), requestLoggerContextKey, true))
 		id := idForRequest(r)
 		proxy := &responseProxy{
 			original: w,
@@ -144,21 +225,43 @@ func (mx *Mux) requestLogger(handler http.Handler) http.Handler {
 			mx.logger.Info("Request finished",
 				zap.String("request_id", id),
 				zap.Int("status", proxy.status),
+				zap.Int64("bytes_written", proxy.bytesWritten),
+				zap.Bool("headers_sent", proxy.headersSent),
 				zap.Duration("duration", time.Since(started)))
 		}()
-		handler.ServeHTTP(proxy, r)
+		handler.ServeHTTP(proxy.wrap(), r)
 	})
 }
 
-func (mx Mux) copy() *Mux {
-	return &mx
+// copy returns a child of the Mux, used by sub-routers. Children resolve error
+// mappings, templates and reporters through their parent at request time, so
+// the ones registered on the parent after the child is created still apply.
+func (mx *Mux) copy() *Mux {
+	c := *mx
+	c.parent = mx
+	c.errorMappings = nil
+	c.errorTemplates = nil
+	c.errorReports = nil
+	return &c
 }
 
+// internalNotFoundDispatch invokes the NotFound handler set on the Mux. When
+// none is set, the default handler is invoked on the Mux itself rather than
+// on the one it was copied from, so sub-routers use their own options and
+// templates. The same happens to internalMethodNotAllowedDispatch.
 func (mx *Mux) internalNotFoundDispatch(w http.ResponseWriter, r *http.Request) {
+	if mx.notFoundHandler == nil {
+		mx.defaultNotFoundHandler(w, r)
+		return
+	}
 	mx.notFoundHandler(w, r)
 }
 
 func (mx *Mux) internalMethodNotAllowedDispatch(w http.ResponseWriter, r *http.Request) {
+	if mx.methodNotAllowedHandler == nil {
+		mx.defaultMethodNotAllowedHandler(w, r)
+		return
+	}
 	mx.methodNotAllowedHandler(w, r)
 }
 
@@ -202,7 +305,7 @@ func (mx *Mux) RequestIdentifierGenerator(fn RequestIdentifierGenerator) {
 // the response. Otherwise, a simpler error message will be returned.
 func (mx *Mux) HandleError(handlerFunc ErrorHandlerFunc) {
 	if handlerFunc == nil {
-		handlerFunc = mx.defaultRuntimeErrorHandler
+		handlerFunc = defaultRuntimeErrorHandler
 	}
 	mx.errorHandler = handlerFunc
 }
@@ -216,27 +319,33 @@ func (mx *Mux) HandleError(handlerFunc ErrorHandlerFunc) {
 // the response. Otherwise, a simpler error message will be returned.
 func (mx *Mux) HandleValidationError(handlerFunc ValidationErrorHandlerFunc) {
 	if handlerFunc == nil {
-		handlerFunc = mx.defaultValidationErrorHandler
+		handlerFunc = defaultValidationErrorHandler
 	}
 	mx.validationErrorHandler = handlerFunc
 }
 
+// HandleValidationErrors sets the error handler for validation errors collected
+// when CollectValidationErrors is set. The default implementation behaves like
+// the default handler set by HandleValidationError, listing every collected
+// error in the response.
+func (mx *Mux) HandleValidationErrors(handlerFunc ValidationErrorsHandlerFunc) {
+	if handlerFunc == nil {
+		handlerFunc = defaultValidationErrorsHandler
+	}
+	mx.validationErrorsHandler = handlerFunc
+}
+
 // NotFound sets a custom http.HandlerFunc for routing paths that could
 // not be found. The default 404 handler is `http.NotFound`.
 func (mx *Mux) NotFound(handler http.HandlerFunc) {
-	if handler == nil {
-		handler = mx.defaultNotFoundHandler
-	}
 	mx.notFoundHandler = handler
 }
 
 // MethodNotAllowed sets a custom http.HandlerFunc for routing paths where the
-// method is unresolved. The default handler returns a 405 with an empty body.
+// method is unresolved. The default handler responds with a 405 using the
+// Mux's error templates. Passing nil restores the default handler.
 func (mx *Mux) MethodNotAllowed(handler http.HandlerFunc) {
-	if handler == nil {
-		handler = mx.internalMethodNotAllowedDispatch
-	}
-	mx.internalMux.MethodNotAllowed(handler)
+	mx.methodNotAllowedHandler = handler
 }
 
 // Use appends a middleware handler to the Mux middleware stack.
@@ -256,16 +365,81 @@ func (mx *Mux) With(middlewares ...func(http.Handler) http.Handler) *Mux {
 	return newMX
 }
 
+// Group creates a new inline Mux with a copy of the current middleware stack.
+// It is useful for defining a group of handlers along the same routing path
+// that use an additional set of middlewares. The new Mux inherits settings
+// from the current one as described by Route.
+func (mx *Mux) Group(fn func(r *Mux)) *Mux {
+	im := mx.With()
+	if fn != nil {
+		fn(im)
+	}
+	return im
+}
+
+// Route creates a new Mux and mounts it along the provided `pattern`. The
+// provided function is invoked with the new Mux, which can then be used to
+// register handlers relative to `pattern`. The sub-Mux shares the handler
+// registry of its parent, so nested routes are visible to development error
+// pages, and inherits its settings as follows:
+//
+//   - Error mappings (MapError), error templates (SetErrorTemplate) and error
+//     reporters (SetErrorReporter) are resolved through the parent when a request
+//     is served, so the ones registered on the parent after Route is called
+//     still apply. The ones registered on the sub-Mux take precedence and do
+//     not affect the parent.
+//   - Exported options (such as Development and ProblemDetails), error and
+//     validation handlers, NotFound and MethodNotAllowed handlers, the logger,
+//     the request identifier generator and body parsers are copied when Route
+//     is called. Changing them on either Mux afterwards does not affect the
+//     other.
+func (mx *Mux) Route(pattern string, fn func(r *Mux)) *Mux {
+	if fn == nil {
+		panic(fmt.Sprintf("raggett: attempting to Route() a nil sub-router on '%s'", pattern))
+	}
+	subMux := mx.copy()
+	subMux.routePrefix = mx.routePrefix + pattern
+	subMux.internalMux = chi.NewMux()
+	subMux.internalMux.NotFound(subMux.internalNotFoundDispatch)
+	subMux.internalMux.MethodNotAllowed(subMux.internalMethodNotAllowedDispatch)
+	fn(subMux)
+	mx.internalMux.Mount(pattern, subMux.internalMux)
+	return subMux
+}
+
+// Mount attaches another http.Handler along the provided `pattern`. When the
+// handler is another *Mux, its handlers are registered on this Mux, making
+// them visible to development error pages, and requests keep the identifier
+// and logging provided by this Mux. Handlers registered on the mounted *Mux
+// after Mount is called are still served, but will not be listed.
+// Other than that, a mounted *Mux is fully independent: it does not inherit
+// options, handlers, error mappings, templates or reporters from this Mux.
+// Use Route to create a sub-Mux inheriting them.
+func (mx *Mux) Mount(pattern string, handler http.Handler) {
+	subMux, ok := handler.(*Mux)
+	if !ok {
+		mx.internalMux.Mount(pattern, handler)
+		return
+	}
+
+	for _, route := range subMux.handlers {
+		for method, meta := range route.handlers {
+			mx.registerHandler(method, pattern+route.pattern, meta)
+		}
+	}
+	mx.internalMux.Mount(pattern, subMux.internalMux)
+}
+
 // Connect adds the route `pattern` that matches a CONNECT http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Connect(pattern string, handlerFn interface{}) {
 	mx.internalMux.Connect(pattern, mx.makeResponder("CONNECT", pattern, handlerFn))
 }
 
 // Delete adds the route `pattern` that matches a DELETE http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Delete(pattern string, handlerFn interface{}) {
 	mx.internalMux.Delete(pattern, mx.makeResponder("DELETE", pattern, handlerFn))
 }
@@ -273,53 +447,59 @@ func (mx *Mux) Delete(pattern string, handlerFn interface{}) {
 // Get adds the route `pattern` that matches a GET http method to
 // execute the `handlerFn` function, which must simply take a Request-based
 // struct and return an optional error.
+// Handlers may also return a response value along with an error, as in
+// func(T) (R, error). When the returned error is nil, the returned value is
+// provided to Request.Respond, taking precedence over any value previously
+// provided to it. Nil values are ignored. R must implement at least one of the
+// responder interfaces (such as JSONResponder), or be an interface type.
 func (mx *Mux) Get(pattern string, handlerFn interface{}) {
 	mx.internalMux.Get(pattern, mx.makeResponder("GET", pattern, handlerFn))
 }
 
 // Head adds the route `pattern` that matches a HEAD http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Head(pattern string, handlerFn interface{}) {
 	mx.internalMux.Head(pattern, mx.makeResponder("HEAD", pattern, handlerFn))
 }
 
 // Options adds the route `pattern` that matches a OPTIONS http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Options(pattern string, handlerFn interface{}) {
 	mx.internalMux.Options(pattern, mx.makeResponder("OPTIONS", pattern, handlerFn))
 }
 
 // Patch adds the route `pattern` that matches a PATCH http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Patch(pattern string, handlerFn interface{}) {
 	mx.internalMux.Patch(pattern, mx.makeResponder("PATCH", pattern, handlerFn))
 }
 
 // Post adds the route `pattern` that matches a POST http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Post(pattern string, handlerFn interface{}) {
 	mx.internalMux.Post(pattern, mx.makeResponder("POST", pattern, handlerFn))
 }
 
 // Put adds the route `pattern` that matches a PUT http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Put(pattern string, handlerFn interface{}) {
 	mx.internalMux.Put(pattern, mx.makeResponder("PUT", pattern, handlerFn))
 }
 
 // Trace adds the route `pattern` that matches a TRACE http method to
 // execute the `handlerFn` function, which must simply take a Request-based
-// struct and return an optional error.
+// struct and return an optional error. See Get for other accepted signatures.
 func (mx *Mux) Trace(pattern string, handlerFn interface{}) {
 	mx.internalMux.Trace(pattern, mx.makeResponder("TRACE", pattern, handlerFn))
 }
 
 func (mx *Mux) registerHandler(method, pattern string, t *handlerMetadata) {
+	pattern = mx.routePrefix + pattern
 	pat, ok := mx.handlers[pattern]
 	if !ok {
 		pat = &routeHandler{
@@ -349,8 +529,16 @@ func badRequestUnexpectedEOF(err error, r *Request) {
 	r.Logger.Error("Rejecting request due to unexpected EOF", zap.Error(err))
 }
 
+// handleRuntimeError reports a given runtime error to the ErrorReporter set
+// on the Mux, if any, and passes it to the error handler. stack is the stack
+// trace of the panic that caused the error, if any.
+func (mx *Mux) handleRuntimeError(err error, stack []StackFrame, r *Request) {
+	mx.reportError(r, err, stack)
+	mx.errorHandler(err, r.httpResponse, r)
+}
+
 func (mx *Mux) makeResponder(method, pattern string, handlerFn interface{}) func(w http.ResponseWriter, r *http.Request) {
-	meta, err := determineFuncParams(handlerFn)
+	meta, err := determineFuncParams(mx, handlerFn)
 	if err != nil {
 		panic(err)
 	}
@@ -365,8 +553,10 @@ func (mx *Mux) makeResponder(method, pattern string, handlerFn interface{}) func
 				return
 			} else if validationErr, ok := runtimeErr.(ValidationError); ok {
 				mx.validationErrorHandler(validationErr, w, req)
+			} else if validationErrs, ok := runtimeErr.(ValidationErrors); ok {
+				mx.validationErrorsHandler(validationErrs, w, req)
 			} else {
-				mx.errorHandler(runtimeErr, w, req)
+				mx.handleRuntimeError(runtimeErr, req.panicStack, req)
 			}
 			return
 		}
@@ -378,8 +568,10 @@ func (mx *Mux) makeResponder(method, pattern string, handlerFn interface{}) func
 						return
 					} else if e, ok := err.(error); ok {
 						r.Logger.Error("Error writing response", zap.Error(e))
+						mx.reportError(r, e, getStack(2))
 					} else {
 						r.Logger.Error("Error writing response. Recovered from non-error panic:", zap.String("error", fmt.Sprintf("%s", e)))
+						mx.reportError(r, recoveredError(err), getStack(2))
 					}
 
 					if !r.flushedHeaders {
@@ -402,6 +594,7 @@ type muxContextKey struct {
 }
 
 var requestIDContextKey = muxContextKey{key: "__raggett_request_mux"}
+var requestLoggerContextKey = muxContextKey{key: "__raggett_request_logger"}
 
 func idForRequest(r *http.Request) string {
 	if r == nil {
@@ -0,0 +1,194 @@
+package raggett
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type routeTestRequest struct {
+	*Request
+	ID string `url-param:"id"`
+}
+
+func TestMuxRoute(t *testing.T) {
+	m := NewMux(zap.NewNop())
+	m.Development = true
+	m.Route("/admin", func(r *Mux) {
+		r.Get("/users/{id}", func(req routeTestRequest) error {
+			req.RespondString("user " + req.ID)
+			return nil
+		})
+		r.Route("/settings", func(r *Mux) {
+			r.Get("/", func(req routeTestRequest) error {
+				req.RespondString("settings")
+				return nil
+			})
+		})
+	})
+
+	code, body := doRequest(m, "text/plain", "GET", "/admin/users/42", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "user 42", body)
+
+	code, body = doRequest(m, "text/plain", "GET", "/admin/settings/", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "settings", body)
+
+	assert.NotNil(t, m.handlerMetaFor("GET", "/admin/users/{id}"))
+	assert.NotNil(t, m.handlerMetaFor("GET", "/admin/settings/"))
+
+	code, body = doRequest(m, "text/plain", "GET", "/admin/nope", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, body, "/admin/users/{id}")
+	assert.Contains(t, body, "raggett.routeTestRequest")
+}
+
+func TestMuxGroup(t *testing.T) {
+	m := NewMux(zap.NewNop())
+	m.Group(func(r *Mux) {
+		r.Use(func(handler http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Group", "true")
+				handler.ServeHTTP(w, r)
+			})
+		})
+		r.Get("/grouped", func(req EmptyRequest) error {
+			return nil
+		})
+	})
+	m.Get("/plain", func(req EmptyRequest) error {
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/grouped", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-Group"))
+
+	req = httptest.NewRequest("GET", "/plain", nil)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Header().Get("X-Group"))
+}
+
+func TestMuxMount(t *testing.T) {
+	m := NewMux(zap.NewNop())
+
+	sub := NewMux(zap.NewNop())
+	sub.Get("/{id}", func(req routeTestRequest) error {
+		req.RespondString("item " + req.ID)
+		return nil
+	})
+	m.Mount("/items", sub)
+	m.Mount("/raw", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("raw"))
+	}))
+
+	code, body := doRequest(m, "text/plain", "GET", "/items/7", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "item 7", body)
+	assert.NotNil(t, m.handlerMetaFor("GET", "/items/{id}"))
+
+	code, body = doRequest(m, "text/plain", "GET", "/raw", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "raw", body)
+}
+
+func TestMuxMountLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	m := NewMux(zap.New(core))
+	m.RequestIdentifierGenerator(func(r *http.Request) string { return "parent-id" })
+
+	sub := NewMux(zap.New(core))
+	sub.RequestIdentifierGenerator(func(r *http.Request) string { return "sub-id" })
+	sub.Get("/{id}", func(req routeTestRequest) error {
+		req.RespondString(req.requestID)
+		return nil
+	})
+	m.Mount("/items", sub)
+
+	r := httptest.NewRequest("GET", "/items/7", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "parent-id", w.Body.String())
+	assert.Equal(t, []string{"parent-id"}, w.Header().Values("Request-ID"))
+	finished := logs.FilterMessage("Request finished").All()
+	require.Len(t, finished, 1)
+	assert.Equal(t, "parent-id", finished[0].ContextMap()["request_id"])
+	assert.Len(t, logs.FilterMessage("Request started").All(), 1)
+}
+
+func TestMuxRouteInheritance(t *testing.T) {
+	errTeapot := errors.New("teapot")
+	m := NewMux(zap.NewNop())
+	m.Route("/api", func(r *Mux) {
+		r.Get("/", func(req EmptyRequest) error {
+			return errTeapot
+		})
+	})
+	m.ProblemDetails = true
+	m.MapError(errTeapot, http.StatusTeapot)
+
+	w, _ := doProblemRequest(m, "application/json", "GET", "/api/")
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "application/json;charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestMuxMountIndependence(t *testing.T) {
+	errTeapot := errors.New("teapot")
+	m := NewMux(zap.NewNop())
+	m.ProblemDetails = true
+	m.MapError(errTeapot, http.StatusTeapot)
+
+	sub := NewMux(zap.NewNop())
+	sub.Get("/", func(req EmptyRequest) error {
+		return errTeapot
+	})
+	m.Mount("/items", sub)
+
+	w, _ := doProblemRequest(m, "application/json", "GET", "/items/")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json;charset=utf-8", w.Header().Get("Content-Type"))
+
+	w, _ = doProblemRequest(m, "application/json", "GET", "/items/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/json;charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestMuxRouteMethodNotAllowed(t *testing.T) {
+	m := NewMux(zap.NewNop())
+	m.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte("custom 405"))
+	})
+	m.Route("/api", func(r *Mux) {
+		r.Route("/v1", func(r *Mux) {
+			r.Get("/items", func(req EmptyRequest) error {
+				return nil
+			})
+		})
+	})
+	m.Get("/items", func(req EmptyRequest) error {
+		return nil
+	})
+
+	code, body := doRequest(m, "text/plain", "POST", "/api/v1/items", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "custom 405", body)
+
+	code, body = doRequest(m, "text/plain", "POST", "/items", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	assert.Equal(t, "custom 405", body)
+}
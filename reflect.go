@@ -1,14 +1,18 @@
 package raggett
 
 import (
+	"fmt"
 	"mime/multipart"
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 )
 
 var requestReflectType = reflect.TypeOf(&Request{})
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
 var customRequestParserType = reflect.TypeOf((*CustomRequestParser)(nil)).Elem()
+var validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
 var multipartFileHeaderField = reflect.TypeOf(multipart.FileHeader{})
 var raggettFileHeaderField = reflect.TypeOf(FileHeader{})
 
@@ -71,11 +75,20 @@ func kindForFileField(input reflect.Type, f reflect.StructField) (fileFieldKind,
 type requestField struct {
 	requestMetadata  *handlerMetadata
 	structField      *reflect.StructField
+	structPath       string
 	requestFieldName string
+	names            []string
 	pattern          *regexp.Regexp
 	required         *bool
 	blank            *bool
 	fileFieldKind    fileFieldKind
+	coercer          coercer
+	isSlice          bool
+	isMap            bool
+	split            string
+	contextKey       interface{}
+	defaultValue     []string
+	constraints      fieldConstraints
 }
 
 type handlerMetadata struct {
@@ -83,13 +96,22 @@ type handlerMetadata struct {
 	wantsPtr        bool
 	requestField    *reflect.StructField
 	customParser    bool
+	validator       bool
 	handlerFunction *reflect.Value
+	responseType    reflect.Type
 	urlParams       map[string]*requestField
 	queryParams     map[string]*requestField
 	body            *requestField
 	bodyKind        string
+	bodyParser      bodyParser
 	headers         map[string]*requestField
+	cookies         map[string]*requestField
+	contextValues   map[string]*requestField
 	forms           map[string]*requestField
+	plan            *bindingPlan
+	// openAPIHidden indicates whether the handler is omitted from OpenAPI
+	// documents, as happens with the one registered by Mux.ServeOpenAPI.
+	openAPIHidden bool
 }
 
 func (hm *handlerMetadata) hasURLParam(name string) bool {
@@ -102,7 +124,110 @@ func (hm *handlerMetadata) hasQueryParam(name string) bool {
 	return ok
 }
 
-func determineFuncParams(fn interface{}) (*handlerMetadata, error) {
+// lookupValues returns values provided for the field under any of its names.
+// Slice fields also receive values from names suffixed with "[]", as in
+// "tags[]=a&tags[]=b".
+func (f *requestField) lookupValues(values map[string][]string) ([]string, bool) {
+	if !f.isSlice {
+		for _, name := range f.names {
+			if val, ok := values[name]; ok {
+				return val, true
+			}
+		}
+		return nil, false
+	}
+
+	var result []string
+	exists := false
+	for _, name := range f.names {
+		for _, key := range []string{name, name + "[]"} {
+			if val, ok := values[key]; ok {
+				result = append(result, val...)
+				exists = true
+			}
+		}
+	}
+	return result, exists
+}
+
+// lookupMap returns values provided for a map field, keyed by the map key
+// provided through bracket ("meta[color]") or dotted ("meta.color") notation.
+func (f *requestField) lookupMap(values map[string][]string) (map[string][]string, bool) {
+	result := map[string][]string{}
+	for key, val := range values {
+		for _, name := range f.names {
+			if mapKey, ok := mapKeyFor(name, key); ok {
+				result[mapKey] = append(result[mapKey], val...)
+			}
+		}
+	}
+	return result, len(result) > 0
+}
+
+// mapKeyFor extracts the map key from a given request value key, in case it
+// refers to the provided field name.
+func mapKeyFor(name, key string) (string, bool) {
+	if !strings.HasPrefix(key, name) {
+		return "", false
+	}
+	rest := key[len(name):]
+	switch {
+	case len(rest) > 2 && rest[0] == '[' && rest[len(rest)-1] == ']':
+		rest = rest[1 : len(rest)-1]
+	case len(rest) > 1 && rest[0] == '.':
+		rest = rest[1:]
+	default:
+		return "", false
+	}
+	if strings.ContainsAny(rest, "[].") {
+		return "", false
+	}
+	return rest, true
+}
+
+// lookupFiles returns files provided for the field under any of its names.
+func (f *requestField) lookupFiles(files map[string][]*multipart.FileHeader) ([]*multipart.FileHeader, bool) {
+	for _, name := range f.names {
+		if val, ok := files[name]; ok {
+			return val, true
+		}
+	}
+	return nil, false
+}
+
+// fieldNamed returns the field loaded from a given request field name (such as
+// "email" in `query:"email"`) or struct field name, along with its kind.
+func (hm *handlerMetadata) fieldNamed(name string) (*requestField, fieldKind, bool) {
+	sources := []struct {
+		kind   fieldKind
+		fields map[string]*requestField
+	}{
+		{fieldKindURLParam, hm.urlParams},
+		{fieldKindQuery, hm.queryParams},
+		{fieldKindHeader, hm.headers},
+		{fieldKindCookie, hm.cookies},
+		{fieldKindContext, hm.contextValues},
+		{fieldKindForm, hm.forms},
+	}
+	for _, src := range sources {
+		if f, ok := src.fields[name]; ok {
+			return f, src.kind, true
+		}
+	}
+	for _, src := range sources {
+		for _, f := range src.fields {
+			if f.structPath == name {
+				return f, src.kind, true
+			}
+		}
+	}
+	if hm.body != nil && hm.body.structPath == name {
+		return hm.body, fieldKindBody, true
+	}
+	return nil, 0, false
+}
+
+func determineFuncParams(mx *Mux, fn interface{}) (*handlerMetadata, error) {
 	val := reflect.ValueOf(fn)
 	fnType := val.Type()
 
@@ -116,10 +241,22 @@ func determineFuncParams(fn interface{}) (*handlerMetadata, error) {
 	}
 
 	outArity := fnType.NumOut()
-	if outArity != 1 {
+	if outArity != 1 && outArity != 2 {
 		return nil, ErrIncorrectOutArity
 	}
 
+	if fnType.Out(outArity-1) != errorType {
+		return nil, ErrIncorrectOutType
+	}
+
+	var responseType reflect.Type
+	if outArity == 2 {
+		responseType = fnType.Out(0)
+		if !isResponderType(responseType) {
+			return nil, ErrIncorrectResponseType
+		}
+	}
+
 	// In must be something inheriting Request
 	input := fnType.In(0)
 	originalInput := input
@@ -143,130 +280,319 @@ func determineFuncParams(fn interface{}) (*handlerMetadata, error) {
 	reqMeta := &handlerMetadata{
 		wantsPtr:        wantsPointer,
 		handlerFunction: &val,
+		responseType:    responseType,
 		structType:      input,
 		requestField:    &reqField,
 		customParser:    originalInput.Implements(customRequestParserType),
+		validator:       reflect.PtrTo(input).Implements(validatorType),
 		urlParams:       map[string]*requestField{},
 		queryParams:     map[string]*requestField{},
 		headers:         map[string]*requestField{},
+		cookies:         map[string]*requestField{},
+		contextValues:   map[string]*requestField{},
 		forms:           map[string]*requestField{},
 	}
 
-	for i := 0; i < input.NumField(); i++ {
-		field := input.Field(i)
-
-		// Must have only one set. More than one is an error.
-		urlParam, hasURLParam := field.Tag.Lookup("url-param")
-		query, hasQuery := field.Tag.Lookup("query")
-		body, hasBody := field.Tag.Lookup("body")
-		form, hasForm := field.Tag.Lookup("form")
-		header, hasHeader := field.Tag.Lookup("header")
-
-		hasResolver := false
-		hasMoreThanOneResolver := false
-		for _, b := range []bool{hasURLParam, hasQuery, hasBody, hasForm, hasHeader} {
-			if b {
-				if hasResolver {
-					hasMoreThanOneResolver = true
-					break
-				}
-				hasResolver = true
-			}
-		}
+	if err := loadFieldsMeta(mx, reqMeta, input, input, fieldScope{}); err != nil {
+		return nil, err
+	}
+
+	if reqMeta.customParser && (reqMeta.body != nil || len(reqMeta.forms) > 0) {
+		return nil, errCustomRequestParserConflict(input)
+	}
+
+	if reqMeta.body != nil && len(reqMeta.forms) > 0 {
+		return nil, errBodyFormsConflict(input)
+	}
+
+	reqMeta.plan = compileBindingPlan(reqMeta)
 
-		blank, hasBlank := field.Tag.Lookup("blank")
-		pattern, hasPattern := field.Tag.Lookup("pattern")
-		required, hasRequired := field.Tag.Lookup("required")
+	return reqMeta, nil
+}
+
+// fieldScope represents the struct whose fields are being loaded by
+// loadFieldsMeta. Fields of the request struct are loaded with an empty scope,
+// while fields of nested structs are loaded with a scope indicating the prefix
+// applied to their names.
+type fieldScope struct {
+	// index is the index sequence of the struct within the request struct.
+	index []int
+	// path contains the names of enclosing nested structs, as provided by
+	// their resolver tags.
+	path []string
+	// structPath is the dotted path of the struct's field within the request
+	// struct, including a trailing dot.
+	structPath string
+	// resolver is the resolver tag fields of a nested struct must use.
+	resolver string
+}
+
+// nested returns the scope for a given field binding a nested struct.
+func (s fieldScope) nested(field reflect.StructField, resolver, name string) fieldScope {
+	return fieldScope{
+		index:      field.Index,
+		path:       append(append([]string{}, s.path...), name),
+		structPath: s.structPath + field.Name + ".",
+		resolver:   resolver,
+	}
+}
+
+// embedded returns the scope for a given embedded struct field, whose fields
+// are flattened into the current scope.
+func (s fieldScope) embedded(field reflect.StructField) fieldScope {
+	s.index = field.Index
+	return s
+}
+
+// name sets the name of a given field, considering the scope's prefix. Nested
+// fields are named after their dotted path (e.g. "filter.status"), and are
+// looked up using both bracket ("filter[status]") and dotted notations.
+func (s fieldScope) name(field *requestField, name string) {
+	if len(s.path) == 0 {
+		field.requestFieldName = name
+		field.names = []string{name}
+		return
+	}
+	field.requestFieldName = strings.Join(s.path, ".") + "." + name
+	field.names = []string{
+		s.path[0] + "[" + strings.Join(append(append([]string{}, s.path[1:]...), name), "][") + "]",
+		field.requestFieldName,
+	}
+}
 
-		hasFields := hasBlank || hasPattern || hasRequired
+// nestedResolver determines whether a given field binds a nested struct,
+// returning the resolver used by it and its name.
+func nestedResolver(field reflect.StructField, hasQuery bool, query string, hasForm bool, form string) (string, string, bool) {
+	if field.Type.Kind() != reflect.Struct || (!hasQuery && !hasForm) {
+		return "", "", false
+	}
+	if _, ok := coercerForType(field.Type, time.RFC3339); ok {
+		return "", "", false
+	}
+	if hasQuery {
+		return "query", query, true
+	}
+	return "form", form, true
+}
 
-		if !hasResolver && !hasFields {
+// loadFieldsMeta loads metadata for all fields of a given struct type into
+// reqMeta. Embedded structs not using a resolver tag are flattened into the
+// current scope.
+func loadFieldsMeta(mx *Mux, reqMeta *handlerMetadata, input, t reflect.Type, scope fieldScope) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		field.Index = append(append([]int{}, scope.index...), i)
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasResolverTag(field) {
+			if err := loadFieldsMeta(mx, reqMeta, input, field.Type, scope.embedded(field)); err != nil {
+				return err
+			}
 			continue
 		}
 
-		if !hasResolver && hasFields {
-			return nil, errFieldsWithoutResolver(input, field)
+		if err := loadFieldMeta(mx, reqMeta, input, field, scope); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if hasMoreThanOneResolver {
-			return nil, errMultipleResolver(input, field)
+func hasResolverTag(field reflect.StructField) bool {
+	for _, tag := range []string{"url-param", "query", "body", "form", "header", "cookie", "context"} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
 		}
+	}
+	return false
+}
 
-		fileFieldDetectedKind, err := kindForFileField(input, field)
-		if err != nil {
-			return nil, err
+// loadFieldMeta loads metadata for a single field into reqMeta.
+func loadFieldMeta(mx *Mux, reqMeta *handlerMetadata, input reflect.Type, field reflect.StructField, scope fieldScope) error {
+	// Must have only one set. More than one is an error.
+	urlParam, hasURLParam := field.Tag.Lookup("url-param")
+	query, hasQuery := field.Tag.Lookup("query")
+	body, hasBody := field.Tag.Lookup("body")
+	form, hasForm := field.Tag.Lookup("form")
+	header, hasHeader := field.Tag.Lookup("header")
+	cookie, hasCookie := field.Tag.Lookup("cookie")
+	contextName, hasContext := field.Tag.Lookup("context")
+
+	hasResolver := false
+	hasMoreThanOneResolver := false
+	for _, b := range []bool{hasURLParam, hasQuery, hasBody, hasForm, hasHeader, hasCookie, hasContext} {
+		if b {
+			if hasResolver {
+				hasMoreThanOneResolver = true
+				break
+			}
+			hasResolver = true
 		}
+	}
 
-		reqField := &requestField{
-			requestMetadata: reqMeta,
-			structField:     &field,
-			fileFieldKind:   fileFieldDetectedKind,
+	blank, hasBlank := field.Tag.Lookup("blank")
+	pattern, hasPattern := field.Tag.Lookup("pattern")
+	required, hasRequired := field.Tag.Lookup("required")
+	format, hasFormat := field.Tag.Lookup("format")
+	defaultValue, hasDefault := field.Tag.Lookup("default")
+	split, hasSplit := field.Tag.Lookup("split")
+
+	hasConstraints := false
+	for _, tag := range constraintTags {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			hasConstraints = true
+			break
 		}
+	}
 
-		if hasBlank {
-			blank := strings.EqualFold(blank, "true")
-			reqField.blank = &blank
+	hasFields := hasBlank || hasPattern || hasRequired || hasFormat || hasDefault || hasSplit || hasConstraints
+
+	if !hasResolver && !hasFields {
+		return nil
+	}
+
+	if !hasResolver && hasFields {
+		return errFieldsWithoutResolver(input, field)
+	}
+
+	if hasMoreThanOneResolver {
+		return errMultipleResolver(input, field)
+	}
+
+	if scope.resolver != "" {
+		if _, ok := field.Tag.Lookup(scope.resolver); !ok {
+			return errInvalidNestedField(input, field, fmt.Errorf("fields of structs bound through %s must use the same resolver", scope.resolver))
 		}
+	}
 
-		if hasPattern {
-			if pattern == "" {
-				return nil, errEmptyPattern(input, field)
-			}
+	fileFieldDetectedKind, err := kindForFileField(input, field)
+	if err != nil {
+		return err
+	}
 
-			pat, err := regexp.Compile(pattern)
-			if err != nil {
-				return nil, errInvalidPattern(input, field, err)
-			}
-			reqField.pattern = pat
+	if resolver, name, ok := nestedResolver(field, hasQuery, query, hasForm, form); ok {
+		if hasFields {
+			return errInvalidNestedField(input, field, fmt.Errorf("validation tags cannot be applied to nested structs"))
+		}
+		return loadFieldsMeta(mx, reqMeta, input, field.Type, scope.nested(field, resolver, name))
+	}
+
+	reqField := &requestField{
+		requestMetadata: reqMeta,
+		structField:     &field,
+		structPath:      scope.structPath + field.Name,
+		fileFieldKind:   fileFieldDetectedKind,
+	}
+
+	if hasBlank {
+		blank := strings.EqualFold(blank, "true")
+		reqField.blank = &blank
+	}
+
+	if hasPattern {
+		if pattern == "" {
+			return errEmptyPattern(input, field)
 		}
 
-		if hasRequired {
-			required := strings.EqualFold(required, "true")
-			reqField.required = &required
+		pat, err := regexp.Compile(pattern)
+		if err != nil {
+			return errInvalidPattern(input, field, err)
 		}
+		reqField.pattern = pat
+	}
 
-		if hasBody {
-			if reqMeta.body != nil {
-				return nil, errMultipleResolver(input, field)
-			}
-			if body == "" {
-				return nil, errEmptyBodyTag(input, field)
-			}
+	if hasRequired {
+		required := strings.EqualFold(required, "true")
+		reqField.required = &required
+	}
 
-			parser, valid := bodyParsers[body]
+	if hasBody {
+		if reqMeta.body != nil {
+			return errMultipleResolver(input, field)
+		}
+		if body == "" {
+			return errEmptyBodyTag(input, field)
+		}
 
+		var parser bodyParser
+		if body == autoBodyParserName {
+			p, valid := mx.autoBodyParser(field.Type)
 			if !valid {
-				return nil, errInvalidBodyParser(input, field)
+				return errAutoBodyParserUnavailable(input, field)
 			}
-
-			if err := parser.typeValidator(field.Type); err != nil {
-				return nil, err
+			parser = p
+		} else {
+			p, valid := mx.bodyParserNamed(body)
+			if !valid {
+				return errInvalidBodyParser(input, field)
 			}
 
-			reqMeta.body = reqField
-			reqMeta.bodyKind = body
-		} else if hasQuery {
-			reqField.requestFieldName = query
-			reqMeta.queryParams[query] = reqField
-		} else if hasURLParam {
-			reqField.requestFieldName = urlParam
-			reqMeta.urlParams[urlParam] = reqField
-		} else if hasHeader {
-			reqField.requestFieldName = header
-			reqMeta.headers[header] = reqField
-		} else if hasForm {
-			reqField.requestFieldName = form
-			reqMeta.forms[form] = reqField
+			if err := p.typeValidator(field.Type); err != nil {
+				return err
+			}
+			parser = p
 		}
-	}
 
-	if reqMeta.customParser && (reqMeta.body != nil || len(reqMeta.forms) > 0) {
-		return nil, errCustomRequestParserConflict(input)
+		reqMeta.body = reqField
+		reqMeta.bodyKind = body
+		reqMeta.bodyParser = parser
+	} else if hasQuery {
+		scope.name(reqField, query)
+		reqMeta.queryParams[reqField.requestFieldName] = reqField
+	} else if hasURLParam {
+		scope.name(reqField, urlParam)
+		reqMeta.urlParams[reqField.requestFieldName] = reqField
+	} else if hasHeader {
+		scope.name(reqField, header)
+		reqMeta.headers[reqField.requestFieldName] = reqField
+	} else if hasCookie {
+		scope.name(reqField, cookie)
+		reqMeta.cookies[reqField.requestFieldName] = reqField
+	} else if hasContext {
+		if hasBlank || hasPattern || hasFormat || hasDefault || hasSplit || hasConstraints {
+			return errInvalidContextField(input, field, fmt.Errorf("only the required tag can be used along with context"))
+		}
+		scope.name(reqField, contextName)
+		if err := resolveContextKey(input, reqField, contextName); err != nil {
+			return err
+		}
+		reqMeta.contextValues[reqField.requestFieldName] = reqField
+		return nil
+	} else if hasForm {
+		scope.name(reqField, form)
+		reqMeta.forms[reqField.requestFieldName] = reqField
 	}
 
-	if reqMeta.body != nil && len(reqMeta.forms) > 0 {
-		return nil, errBodyFormsConflict(input)
+	if !hasBody && !fileFieldDetectedKind.IsFile() {
+		if err := resolveCoercer(input, reqField, format, hasFormat); err != nil {
+			return err
+		}
+		if reqField.isMap && !hasQuery && !hasForm {
+			return errUnsupportedFieldType(input, field, field.Type)
+		}
+		if hasSplit {
+			if err := resolveSplit(input, reqField, split); err != nil {
+				return err
+			}
+		}
+		if hasDefault {
+			if err := resolveDefault(input, reqField, defaultValue); err != nil {
+				return err
+			}
+		}
+		if hasConstraints {
+			if err := resolveConstraints(input, reqField); err != nil {
+				return err
+			}
+		}
+	} else if hasFormat {
+		return errInvalidFormatTag(input, field)
+	} else if hasSplit {
+		return errInvalidSplitTag(input, field)
+	} else if hasDefault {
+		return errInvalidDefault(input, field, errDefaultNotSupported)
+	} else if hasConstraints {
+		return errInvalidConstraint(input, field, errConstraintNotSupported)
 	}
 
-	return reqMeta, nil
+	return nil
 }
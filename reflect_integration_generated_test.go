@@ -447,47 +447,47 @@ func assertIntegrationRequestData(t *testing.T, r *GeneratedRequestType) {
 	t.Run(`Received byte-url-param equals 255`, func(t *testing.T) {
 		assert.Equal(t, byte(255), r.ByteURLParam)
 	})
-	t.Run(`Received float32-form equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float32(340282350000000000000000000000000000000), r.Float32Form)
+	t.Run(`Received float32-form equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, float32(3.4028235e+38), r.Float32Form)
 	})
-	t.Run(`Received float32-form-arr equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float32{340282350000000000000000000000000000000, 340282350000000000000000000000000000000}, r.Float32FormArr)
+	t.Run(`Received float32-form-arr equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, []float32{3.4028235e+38, 3.4028235e+38}, r.Float32FormArr)
 	})
-	t.Run(`Received float32-header equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float32(340282350000000000000000000000000000000), r.Float32Header)
+	t.Run(`Received float32-header equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, float32(3.4028235e+38), r.Float32Header)
 	})
-	t.Run(`Received float32-header-arr equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float32{340282350000000000000000000000000000000, 340282350000000000000000000000000000000}, r.Float32HeaderArr)
+	t.Run(`Received float32-header-arr equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, []float32{3.4028235e+38, 3.4028235e+38}, r.Float32HeaderArr)
 	})
-	t.Run(`Received float32-query equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float32(340282350000000000000000000000000000000), r.Float32Query)
+	t.Run(`Received float32-query equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, float32(3.4028235e+38), r.Float32Query)
 	})
-	t.Run(`Received float32-query-arr equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float32{340282350000000000000000000000000000000, 340282350000000000000000000000000000000}, r.Float32QueryArr)
+	t.Run(`Received float32-query-arr equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, []float32{3.4028235e+38, 3.4028235e+38}, r.Float32QueryArr)
 	})
-	t.Run(`Received float32-url-param equals 340282350000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float32(340282350000000000000000000000000000000), r.Float32URLParam)
+	t.Run(`Received float32-url-param equals 3.4028235e+38`, func(t *testing.T) {
+		assert.Equal(t, float32(3.4028235e+38), r.Float32URLParam)
 	})
-	t.Run(`Received float64-form equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float64(179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000), r.Float64Form)
+	t.Run(`Received float64-form equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, float64(1.7976931348623157e+308), r.Float64Form)
 	})
-	t.Run(`Received float64-form-arr equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float64{179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000, 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000}, r.Float64FormArr)
+	t.Run(`Received float64-form-arr equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, []float64{1.7976931348623157e+308, 1.7976931348623157e+308}, r.Float64FormArr)
 	})
-	t.Run(`Received float64-header equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float64(179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000), r.Float64Header)
+	t.Run(`Received float64-header equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, float64(1.7976931348623157e+308), r.Float64Header)
 	})
-	t.Run(`Received float64-header-arr equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float64{179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000, 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000}, r.Float64HeaderArr)
+	t.Run(`Received float64-header-arr equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, []float64{1.7976931348623157e+308, 1.7976931348623157e+308}, r.Float64HeaderArr)
 	})
-	t.Run(`Received float64-query equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float64(179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000), r.Float64Query)
+	t.Run(`Received float64-query equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, float64(1.7976931348623157e+308), r.Float64Query)
 	})
-	t.Run(`Received float64-query-arr equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, []float64{179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000, 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000}, r.Float64QueryArr)
+	t.Run(`Received float64-query-arr equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, []float64{1.7976931348623157e+308, 1.7976931348623157e+308}, r.Float64QueryArr)
 	})
-	t.Run(`Received float64-url-param equals 179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000`, func(t *testing.T) {
-		assert.Equal(t, float64(179769313486231570000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000), r.Float64URLParam)
+	t.Run(`Received float64-url-param equals 1.7976931348623157e+308`, func(t *testing.T) {
+		assert.Equal(t, float64(1.7976931348623157e+308), r.Float64URLParam)
 	})
 	t.Run(`Received int-form equals 65540`, func(t *testing.T) {
 		assert.Equal(t, int(65540), r.IntForm)
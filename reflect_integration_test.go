@@ -2,12 +2,18 @@ package raggett
 
 import (
 	"encoding/xml"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -163,6 +169,545 @@ func TestStreamLoader(t *testing.T) {
 	assert.Equal(t, []byte(data), res)
 }
 
+type csvRow []string
+
+func csvBodyParser() BodyParser {
+	return BodyParser{
+		ValidateType: func(t reflect.Type) error {
+			if t != reflect.TypeOf(csvRow{}) {
+				return fmt.Errorf("cannot use csv body on type %s", t)
+			}
+			return nil
+		},
+		Decode: func(r *http.Request, into interface{}) error {
+			data, err := io.ReadAll(r.Body)
+			if err != nil {
+				return err
+			}
+			*into.(*csvRow) = strings.Split(string(data), ",")
+			return nil
+		},
+	}
+}
+
+func TestCustomBodyParser(t *testing.T) {
+	type RequestType struct {
+		*Request
+		Row csvRow `body:"csv-test"`
+	}
+
+	t.Run("Unregistered", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(RequestType) error { return nil })
+		assert.IsType(t, ErrInvalidBodyParser{}, err)
+	})
+
+	t.Run("Mux", func(t *testing.T) {
+		m := NewMux(zap.NewNop())
+		m.RegisterBodyParser("csv-test", csvBodyParser())
+		var row csvRow
+		m.Post("/", func(r RequestType) error {
+			row = r.Row
+			return nil
+		})
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("a,b,c"))
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, csvRow{"a", "b", "c"}, row)
+
+		// Parsers registered on a Mux must not be available globally.
+		_, err := determineFuncParams(nil, func(RequestType) error { return nil })
+		assert.IsType(t, ErrInvalidBodyParser{}, err)
+	})
+
+	t.Run("Type validation", func(t *testing.T) {
+		type InvalidRequestType struct {
+			*Request
+			Row string `body:"csv-test"`
+		}
+		m := NewMux(zap.NewNop())
+		m.RegisterBodyParser("csv-test", csvBodyParser())
+		assert.Panics(t, func() {
+			m.Post("/", func(InvalidRequestType) error { return nil })
+		})
+	})
+
+	t.Run("Invalid registration", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterBodyParser("", csvBodyParser()) })
+		assert.Panics(t, func() { RegisterBodyParser("csv-test", BodyParser{}) })
+	})
+}
+
+func TestGlobalBodyParser(t *testing.T) {
+	type RequestType struct {
+		*Request
+		Row csvRow `body:"csv-global-test"`
+	}
+
+	RegisterBodyParser("csv-global-test", csvBodyParser())
+	req := httptest.NewRequest("POST", "/", strings.NewReader("x,y"))
+	var r RequestType
+	w, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req RequestType) error {
+		r = req
+		return nil
+	})
+	require.NoError(t, validationError)
+	require.NoError(t, runtimeError)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, csvRow{"x", "y"}, r.Row)
+}
+
+func TestJSONLoaderMap(t *testing.T) {
+	type RequestType struct {
+		*Request
+		Data map[string]string `body:"json"`
+	}
+
+	req := httptest.NewRequest("POST", "/foo", strings.NewReader(`{"name": "Raggett"}`))
+	var r *RequestType
+	w, validationError, runtimeError := testMuxPostWith(t, req, "/foo", func(req *RequestType) error {
+		r = req
+		return nil
+	})
+	require.NoError(t, validationError)
+	require.NoError(t, runtimeError)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, map[string]string{"name": "Raggett"}, r.Data)
+}
+
+func TestAutoLoader(t *testing.T) {
+	type Content struct {
+		XMLName xml.Name `json:"-" xml:"data"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	type RequestType struct {
+		*Request
+		Data Content `body:"auto"`
+	}
+
+	tests := []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{"application/json", `{"name": "Raggett"}`, http.StatusNoContent},
+		{"application/json; charset=utf-8", `{"name": "Raggett"}`, http.StatusNoContent},
+		{"application/vnd.raggett+json", `{"name": "Raggett"}`, http.StatusNoContent},
+		{"application/xml", `<data><name>Raggett</name></data>`, http.StatusNoContent},
+		{"text/xml", `<data><name>Raggett</name></data>`, http.StatusNoContent},
+		{"application/json", `{"name": `, http.StatusBadRequest},
+		{"text/csv", `Raggett`, http.StatusUnsupportedMediaType},
+		{"", `Raggett`, http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/foo", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			var r RequestType
+			w, _, runtimeError := testMuxPostWith(t, req, "/foo", func(req RequestType) error {
+				r = req
+				return nil
+			})
+			if tt.status == http.StatusUnsupportedMediaType {
+				var httpErr *HTTPError
+				require.ErrorAs(t, runtimeError, &httpErr)
+				assert.Equal(t, tt.status, httpErr.Status)
+				return
+			}
+			require.NoError(t, runtimeError)
+			require.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusNoContent {
+				assert.Equal(t, "Raggett", r.Data.Name)
+			}
+		})
+	}
+
+	t.Run("Unavailable", func(t *testing.T) {
+		type InvalidRequestType struct {
+			*Request
+			Data int `body:"auto"`
+		}
+		_, err := determineFuncParams(nil, func(InvalidRequestType) error { return nil })
+		assert.IsType(t, ErrAutoBodyParserUnavailable{}, err)
+	})
+
+	t.Run("Custom parser", func(t *testing.T) {
+		type CSVRequestType struct {
+			*Request
+			Row csvRow `body:"auto"`
+		}
+		parser := csvBodyParser()
+		parser.MediaTypes = []string{"text/csv"}
+		m := NewMux(zap.NewNop())
+		m.RegisterBodyParser("csv-test", parser)
+		var row csvRow
+		m.Post("/", func(r CSVRequestType) error {
+			row = r.Row
+			return nil
+		})
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader("a,b"))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, csvRow{"a", "b"}, row)
+	})
+}
+
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestTextTypesLoader(t *testing.T) {
+	type request struct {
+		*Request
+		At       time.Time       `query:"at"`
+		Day      time.Time       `query:"day" format:"2006-01-02"`
+		Days     []time.Time     `query:"days" format:"2006-01-02"`
+		Timeout  time.Duration   `query:"timeout"`
+		ID       uuid.UUID       `url-param:"id"`
+		IDs      []uuid.UUID     `query:"ids"`
+		Addr     net.IP          `header:"X-Addr"`
+		Callback *url.URL        `query:"callback"`
+		Name     upperText       `query:"name"`
+		Names    []upperText     `query:"names"`
+		Timeouts []time.Duration `query:"timeouts"`
+	}
+
+	id := uuid.New()
+	other := uuid.New()
+	q := url.Values{}
+	q.Set("at", "2021-01-02T03:04:05Z")
+	q.Set("day", "2021-05-06")
+	q.Add("days", "2021-05-06")
+	q.Add("days", "2021-05-07")
+	q.Set("timeout", "1m30s")
+	q.Add("ids", id.String())
+	q.Add("ids", other.String())
+	q.Set("callback", "https://example.com/cb?x=1")
+	q.Set("name", "foo")
+	q.Add("names", "a")
+	q.Add("names", "b")
+	q.Add("timeouts", "1s")
+
+	req := httptest.NewRequest("POST", "/"+id.String()+"?"+q.Encode(), nil)
+	req.Header.Set("X-Addr", "192.168.0.1")
+
+	var r *request
+	w, validationError, runtimeError := testMuxPostWith(t, req, "/{id}", func(req *request) error {
+		r = req
+		return nil
+	})
+	require.NoError(t, validationError)
+	require.NoError(t, runtimeError)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	assert.Equal(t, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC), r.At.UTC())
+	assert.Equal(t, time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC), r.Day)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, 5, 7, 0, 0, 0, 0, time.UTC),
+	}, r.Days)
+	assert.Equal(t, 90*time.Second, r.Timeout)
+	assert.Equal(t, id, r.ID)
+	assert.Equal(t, []uuid.UUID{id, other}, r.IDs)
+	assert.Equal(t, net.ParseIP("192.168.0.1"), r.Addr)
+	assert.Equal(t, "https://example.com/cb?x=1", r.Callback.String())
+	assert.Equal(t, upperText("FOO"), r.Name)
+	assert.Equal(t, []upperText{"A", "B"}, r.Names)
+	assert.Equal(t, []time.Duration{time.Second}, r.Timeouts)
+
+	t.Run("invalid value", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/not-an-uuid", nil)
+		_, validationError, _ := testMuxPostWith(t, req, "/{id}", func(req *request) error {
+			return nil
+		})
+		require.Error(t, validationError)
+		vErr := validationError.(ValidationError)
+		assert.Equal(t, ValidationErrorKindParsing, vErr.ErrorKind)
+		assert.Equal(t, "id", vErr.FieldName)
+	})
+}
+
+func TestOptionalAndDefaultValues(t *testing.T) {
+	type request struct {
+		*Request
+		Page     *int           `query:"page"`
+		Enabled  *bool          `query:"enabled"`
+		Name     *string        `query:"name"`
+		ID       *uuid.UUID     `query:"id"`
+		PerPage  int            `query:"per_page" default:"25"`
+		Sort     *string        `query:"sort" default:"name" pattern:"^(name|date)$"`
+		Timeout  time.Duration  `header:"X-Timeout" default:"5s"`
+		Statuses []string       `query:"status" default:"open"`
+		Limit    *time.Duration `query:"limit"`
+	}
+
+	t.Run("absent values", func(t *testing.T) {
+		var r *request
+		req := httptest.NewRequest("POST", "/", nil)
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+
+		assert.Nil(t, r.Page)
+		assert.Nil(t, r.Enabled)
+		assert.Nil(t, r.Name)
+		assert.Nil(t, r.ID)
+		assert.Nil(t, r.Limit)
+		assert.Equal(t, 25, r.PerPage)
+		require.NotNil(t, r.Sort)
+		assert.Equal(t, "name", *r.Sort)
+		assert.Equal(t, 5*time.Second, r.Timeout)
+		assert.Equal(t, []string{"open"}, r.Statuses)
+	})
+
+	t.Run("present values", func(t *testing.T) {
+		var r *request
+		id := uuid.New()
+		req := httptest.NewRequest("POST", "/?page=0&enabled=false&name=&id="+id.String()+"&per_page=10&sort=date&status=a&status=b", nil)
+		req.Header.Set("X-Timeout", "1m")
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+
+		require.NotNil(t, r.Page)
+		assert.Equal(t, 0, *r.Page)
+		require.NotNil(t, r.Enabled)
+		assert.False(t, *r.Enabled)
+		require.NotNil(t, r.Name)
+		assert.Equal(t, "", *r.Name)
+		require.NotNil(t, r.ID)
+		assert.Equal(t, id, *r.ID)
+		assert.Equal(t, 10, r.PerPage)
+		assert.Equal(t, "date", *r.Sort)
+		assert.Equal(t, time.Minute, r.Timeout)
+		assert.Equal(t, []string{"a", "b"}, r.Statuses)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/?page=foo", nil)
+		_, validationError, _ := testMuxPostWith(t, req, "/", func(req *request) error {
+			return nil
+		})
+		require.Error(t, validationError)
+		assert.Equal(t, "page", validationError.(ValidationError).FieldName)
+	})
+}
+
+type pagination struct {
+	Page    int `query:"page" default:"1" min:"1"`
+	PerPage int `query:"per_page" default:"25" max:"100"`
+}
+
+func TestNestedStructsLoader(t *testing.T) {
+	type request struct {
+		*Request
+		pagination
+		Filter struct {
+			Status string `query:"status" oneof:"open|closed"`
+			Owner  struct {
+				Name string `query:"name"`
+			} `query:"owner"`
+		} `query:"filter"`
+		Profile struct {
+			Name string `form:"name" required:"true"`
+		} `form:"profile"`
+	}
+
+	t.Run("bracket notation", func(t *testing.T) {
+		var r *request
+		form := url.Values{"profile[name]": {"Paul"}}
+		req := httptest.NewRequest("POST", "/?page=2&filter[status]=open&filter[owner][name]=vito", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+
+		assert.Equal(t, 2, r.Page)
+		assert.Equal(t, 25, r.PerPage)
+		assert.Equal(t, "open", r.Filter.Status)
+		assert.Equal(t, "vito", r.Filter.Owner.Name)
+		assert.Equal(t, "Paul", r.Profile.Name)
+	})
+
+	t.Run("dotted notation", func(t *testing.T) {
+		var r *request
+		form := url.Values{"profile.name": {"Paul"}}
+		req := httptest.NewRequest("POST", "/?filter.status=closed&filter.owner.name=vito", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+
+		assert.Equal(t, 1, r.Page)
+		assert.Equal(t, "closed", r.Filter.Status)
+		assert.Equal(t, "vito", r.Filter.Owner.Name)
+		assert.Equal(t, "Paul", r.Profile.Name)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		form := url.Values{"profile[name]": {"Paul"}}
+		req := httptest.NewRequest("POST", "/?filter[status]=pending", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, validationError, _ := testMuxPostWith(t, req, "/", func(req *request) error {
+			return nil
+		})
+		require.Error(t, validationError)
+		vErr := validationError.(ValidationError)
+		assert.Equal(t, "filter.status", vErr.FieldName)
+		assert.Equal(t, "Filter.Status", vErr.StructFieldName)
+		assert.Equal(t, ValidationErrorKindOneOf, vErr.ErrorKind)
+	})
+
+	t.Run("embedded values", func(t *testing.T) {
+		form := url.Values{"profile[name]": {"Paul"}}
+		req := httptest.NewRequest("POST", "/?per_page=500", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, validationError, _ := testMuxPostWith(t, req, "/", func(req *request) error {
+			return nil
+		})
+		require.Error(t, validationError)
+		assert.Equal(t, "per_page", validationError.(ValidationError).FieldName)
+		assert.Equal(t, "PerPage", validationError.(ValidationError).StructFieldName)
+	})
+}
+
+func TestBracketNotationLoader(t *testing.T) {
+	type request struct {
+		*Request
+		Tags   []string          `query:"tags"`
+		IDs    []int             `query:"ids" split:","`
+		Meta   map[string]string `query:"meta"`
+		Limits map[string]int    `query:"limits" maxitems:"2" max:"10"`
+		Fields []string          `header:"X-Fields" split:","`
+		Levels []int             `query:"levels" split:"," default:"1,2"`
+		Filter struct {
+			Labels map[string]string `query:"labels"`
+		} `query:"filter"`
+	}
+
+	t.Run("present values", func(t *testing.T) {
+		var r *request
+		req := httptest.NewRequest("POST", "/?tags[]=a&tags[]=b&tags=c&ids=1,2,,3&meta[color]=red&meta.size=L&meta[a][b]=x&limits[x]=1&filter[labels][team]=core", nil)
+		req.Header.Set("X-Fields", "id,name")
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, r.Tags)
+		assert.Equal(t, []int{1, 2, 3}, r.IDs)
+		assert.Equal(t, map[string]string{"color": "red", "size": "L"}, r.Meta)
+		assert.Equal(t, map[string]int{"x": 1}, r.Limits)
+		assert.Equal(t, []string{"id", "name"}, r.Fields)
+		assert.Equal(t, []int{1, 2}, r.Levels)
+		assert.Equal(t, map[string]string{"team": "core"}, r.Filter.Labels)
+	})
+
+	t.Run("absent values", func(t *testing.T) {
+		var r *request
+		req := httptest.NewRequest("POST", "/", nil)
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+		assert.Nil(t, r.Meta)
+		assert.Empty(t, r.Tags)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for query, kind := range map[string]ValidationErrorKind{
+			"limits[a]=foo":                       ValidationErrorKindParsing,
+			"limits[a]=11":                        ValidationErrorKindMax,
+			"limits[a]=1&limits[b]=2&limits[c]=3": ValidationErrorKindMaxItems,
+			"ids=1,a":                             ValidationErrorKindParsing,
+		} {
+			req := httptest.NewRequest("POST", "/?"+query, nil)
+			_, validationError, _ := testMuxPostWith(t, req, "/", func(req *request) error {
+				return nil
+			})
+			require.Error(t, validationError, query)
+			assert.Equal(t, kind, validationError.(ValidationError).ErrorKind, query)
+		}
+	})
+}
+
+func TestCookieLoader(t *testing.T) {
+	type request struct {
+		*Request
+		Session uuid.UUID `cookie:"session_id" required:"true"`
+		Theme   string    `cookie:"theme" pattern:"^(light|dark)$" default:"light"`
+		Visits  *int      `cookie:"visits"`
+	}
+
+	t.Run("present values", func(t *testing.T) {
+		var r *request
+		id := uuid.New()
+		req := httptest.NewRequest("POST", "/", nil)
+		req.AddCookie(&http.Cookie{Name: "session_id", Value: id.String()})
+		req.AddCookie(&http.Cookie{Name: "visits", Value: "3"})
+		_, validationError, runtimeError := testMuxPostWith(t, req, "/", func(req *request) error {
+			r = req
+			return nil
+		})
+		require.NoError(t, validationError)
+		require.NoError(t, runtimeError)
+		assert.Equal(t, id, r.Session)
+		assert.Equal(t, "light", r.Theme)
+		require.NotNil(t, r.Visits)
+		assert.Equal(t, 3, *r.Visits)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		for _, tc := range []struct {
+			cookies []*http.Cookie
+			field   string
+			kind    ValidationErrorKind
+		}{
+			{nil, "session_id", ValidationErrorKindRequired},
+			{[]*http.Cookie{{Name: "session_id", Value: "foo"}}, "session_id", ValidationErrorKindParsing},
+			{[]*http.Cookie{{Name: "session_id", Value: uuid.NewString()}, {Name: "theme", Value: "blue"}}, "theme", ValidationErrorKindPattern},
+		} {
+			req := httptest.NewRequest("POST", "/", nil)
+			for _, c := range tc.cookies {
+				req.AddCookie(c)
+			}
+			_, validationError, _ := testMuxPostWith(t, req, "/", func(req *request) error {
+				return nil
+			})
+			require.Error(t, validationError)
+			vErr := validationError.(ValidationError)
+			assert.Equal(t, tc.field, vErr.FieldName)
+			assert.Equal(t, tc.kind, vErr.ErrorKind)
+			assert.Equal(t, fieldKindCookie, vErr.FieldKind)
+		}
+	})
+}
+
 func testMuxPostWith(t *testing.T, request *http.Request, pattern string, handler interface{}) (resp *httptest.ResponseRecorder, validationError, runtimeError error) {
 	m := NewMux(zap.NewNop())
 	m.HandleValidationError(func(err ValidationError, w http.ResponseWriter, r *Request) {
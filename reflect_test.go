@@ -3,6 +3,7 @@ package raggett
 import (
 	"io"
 	"mime/multipart"
+	"reflect"
 	"testing"
 	"time"
 
@@ -24,32 +25,55 @@ func Test_determineFuncParams(t *testing.T) {
 	t.Parallel()
 
 	t.Run("With invalid function", func(t *testing.T) {
-		_, err := determineFuncParams("nope")
+		_, err := determineFuncParams(nil, "nope")
 		assert.Equal(t, ErrNoFunction, err)
 	})
 
 	t.Run("With invalid argument arity", func(t *testing.T) {
-		_, err := determineFuncParams(func() string { return "foo" })
+		_, err := determineFuncParams(nil, func() string { return "foo" })
 		assert.Equal(t, ErrIncorrectArgsArity, err)
 	})
 
 	t.Run("With invalid output arity", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo string) {})
+		_, err := determineFuncParams(nil, func(foo string) {})
 		assert.Equal(t, ErrIncorrectOutArity, err)
 	})
 
+	t.Run("With invalid output type", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo EmptyRequest) string { return "" })
+		assert.Equal(t, ErrIncorrectOutType, err)
+		_, err = determineFuncParams(nil, func(foo EmptyRequest) (error, string) { return nil, "" })
+		assert.Equal(t, ErrIncorrectOutType, err)
+	})
+
+	t.Run("With response type", func(t *testing.T) {
+		meta, err := determineFuncParams(nil, func(foo EmptyRequest) (*negotiationResponderTest, error) { return nil, nil })
+		assert.NoError(t, err)
+		assert.Equal(t, reflect.TypeOf(&negotiationResponderTest{}), meta.responseType)
+
+		_, err = determineFuncParams(nil, func(foo EmptyRequest) (interface{}, error) { return nil, nil })
+		assert.NoError(t, err)
+	})
+
+	t.Run("With invalid response type", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo EmptyRequest) (string, error) { return "", nil })
+		assert.Equal(t, ErrIncorrectResponseType, err)
+		_, err = determineFuncParams(nil, func(foo EmptyRequest) (*Problem, error) { return nil, nil })
+		assert.Equal(t, ErrIncorrectResponseType, err)
+	})
+
 	t.Run("With invalid argument type", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo string) error { return nil })
+		_, err := determineFuncParams(nil, func(foo string) error { return nil })
 		assert.Equal(t, ErrIncorrectArgType, err)
 	})
 
 	t.Run("With invalid argument struct", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct{}) error { return nil })
+		_, err := determineFuncParams(nil, func(foo struct{}) error { return nil })
 		assert.Equal(t, ErrIncorrectArgType, err)
 	})
 
 	t.Run("With empty pattern", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			Patt string `form:"foo" pattern:""`
 		}) error {
@@ -59,7 +83,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With invalid pattern", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			Patt string `form:"foo" pattern:"(["`
 		}) error {
@@ -69,7 +93,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With multiple resolvers", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A map[string]interface{} `body:"json"`
 			B map[string]interface{} `body:"xml"`
@@ -80,7 +104,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With fields without resolver", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A map[string]interface{} `required:"true"`
 			B map[string]interface{} `body:"xml"`
@@ -92,7 +116,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("with fields with multiple resolvers", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A string `form:"json" header:"foo"`
 		}) error {
@@ -102,7 +126,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With empty body tag", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A interface{} `body:""`
 		}) error {
@@ -112,7 +136,7 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With empty body tag", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A interface{} `body:"foobar"`
 		}) error {
@@ -122,14 +146,14 @@ func Test_determineFuncParams(t *testing.T) {
 	})
 
 	t.Run("With request parser conflicts", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo requestWithParserConflict) error {
+		_, err := determineFuncParams(nil, func(foo requestWithParserConflict) error {
 			return nil
 		})
 		assert.IsType(t, ErrCustomRequestParserConflict{}, err)
 	})
 
 	t.Run("With request body-form conflicts", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct {
+		_, err := determineFuncParams(nil, func(foo struct {
 			*Request
 			A map[string]interface{} `body:"json"`
 			B string                 `form:"foo"`
@@ -139,8 +163,152 @@ func Test_determineFuncParams(t *testing.T) {
 		assert.IsType(t, ErrBodyFormsConflict{}, err)
 	})
 
+	t.Run("With nested field using another resolver", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A struct {
+				B string `header:"b"`
+			} `query:"a"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidNestedField{}, err)
+	})
+
+	t.Run("With validation tags on nested field", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A struct {
+				B string `query:"b"`
+			} `query:"a" required:"true"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidNestedField{}, err)
+	})
+
+	t.Run("With invalid split tag", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A string `query:"a" split:","`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidSplitTag{}, err)
+	})
+
+	t.Run("With map header", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A map[string]string `header:"a"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrUnsupportedFieldType{}, err)
+	})
+
+	t.Run("With unsupported field type", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A complex128 `query:"a"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrUnsupportedFieldType{}, err)
+	})
+
+	t.Run("With format tag on non-time field", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A string `query:"a" format:"2006-01-02"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidFormatTag{}, err)
+	})
+
+	t.Run("With empty format tag", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A time.Time `query:"a" format:""`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidFormatTag{}, err)
+	})
+
+	t.Run("With invalid default", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A int `query:"a" default:"foo"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidDefault{}, err)
+	})
+
+	t.Run("With default on body field", func(t *testing.T) {
+		_, err := determineFuncParams(nil, func(foo struct {
+			*Request
+			A string `body:"text" default:"foo"`
+		}) error {
+			return nil
+		})
+		assert.IsType(t, ErrInvalidDefault{}, err)
+	})
+
+	t.Run("With invalid constraints", func(t *testing.T) {
+		for name, fn := range map[string]interface{}{
+			"min on string": func(foo struct {
+				*Request
+				A string `query:"a" min:"1"`
+			}) error {
+				return nil
+			},
+			"invalid max": func(foo struct {
+				*Request
+				A int `query:"a" max:"foo"`
+			}) error {
+				return nil
+			},
+			"min greater than max": func(foo struct {
+				*Request
+				A int `query:"a" min:"2" max:"1"`
+			}) error {
+				return nil
+			},
+			"minlen on int": func(foo struct {
+				*Request
+				A int `query:"a" minlen:"1"`
+			}) error {
+				return nil
+			},
+			"maxitems on scalar": func(foo struct {
+				*Request
+				A string `query:"a" maxitems:"1"`
+			}) error {
+				return nil
+			},
+			"invalid oneof value": func(foo struct {
+				*Request
+				A int `query:"a" oneof:"1|b"`
+			}) error {
+				return nil
+			},
+			"constraint on body": func(foo struct {
+				*Request
+				A string `body:"text" maxlen:"1"`
+			}) error {
+				return nil
+			},
+		} {
+			_, err := determineFuncParams(nil, fn)
+			assert.IsType(t, ErrInvalidConstraint{}, err, name)
+		}
+	})
+
 	t.Run("With valid signature", func(t *testing.T) {
-		_, err := determineFuncParams(func(foo struct{ *Request }) error { return nil })
+		_, err := determineFuncParams(nil, func(foo struct{ *Request }) error { return nil })
 		assert.NoError(t, err)
 	})
 
@@ -154,7 +322,7 @@ func Test_determineFuncParams(t *testing.T) {
 			Struct   map[string]interface{} `body:"json"`
 		}
 
-		meta, err := determineFuncParams(func(requestType SomeRequestType) error { return nil })
+		meta, err := determineFuncParams(nil, func(requestType SomeRequestType) error { return nil })
 		require.NoError(t, err)
 		assert.NotNil(t, meta)
 		assert.Truef(t, meta.hasURLParam("user"), "should have url-param user")
@@ -223,10 +391,10 @@ func TestUnmarshalerTypeValidation(t *testing.T) {
 }
 
 func performUnmarshalerTest(t *testing.T, valid, invalid interface{}, expectedType string) {
-	meta, err := determineFuncParams(invalid)
+	meta, err := determineFuncParams(nil, invalid)
 	assert.Error(t, err)
 	assert.Nil(t, meta)
-	meta, err = determineFuncParams(valid)
+	meta, err = determineFuncParams(nil, valid)
 	assert.NoError(t, err)
 	require.NotNil(t, meta)
 	assert.Equal(t, expectedType, meta.bodyKind)
@@ -285,7 +453,7 @@ func TestOtherUnmarshalerValidation(t *testing.T) {
 }
 
 func TestCustomRequestParser(t *testing.T) {
-	meta, err := determineFuncParams(func(req *CustomParserRequestStruct) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *CustomParserRequestStruct) error { return nil })
 	require.NoError(t, err)
 	require.True(t, meta.customParser)
 }
@@ -295,7 +463,7 @@ func TestFileFieldRaggettLibSingle(t *testing.T) {
 		*Request
 		File *FileHeader `form:"foo"`
 	}
-	meta, err := determineFuncParams(func(req *FileStruct) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *FileStruct) error { return nil })
 	require.NoError(t, err)
 	require.Equal(t, FileFieldKindRaggettLib, meta.forms["foo"].fileFieldKind)
 }
@@ -305,7 +473,7 @@ func TestFileFieldRaggettLibMulti(t *testing.T) {
 		*Request
 		File []*FileHeader `form:"foo"`
 	}
-	meta, err := determineFuncParams(func(req *FileStruct) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *FileStruct) error { return nil })
 	require.NoError(t, err)
 	require.Equal(t, FileFieldKindRaggettLib|FileFieldKindSlice, meta.forms["foo"].fileFieldKind)
 }
@@ -315,7 +483,7 @@ func TestFileFieldStdLibSingle(t *testing.T) {
 		*Request
 		File *multipart.FileHeader `form:"foo"`
 	}
-	meta, err := determineFuncParams(func(req *FileStruct) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *FileStruct) error { return nil })
 	require.NoError(t, err)
 	require.Equal(t, FileFieldKindStdLib, meta.forms["foo"].fileFieldKind)
 }
@@ -325,7 +493,7 @@ func TestFileFieldStdLibMulti(t *testing.T) {
 		*Request
 		File []*multipart.FileHeader `form:"foo"`
 	}
-	meta, err := determineFuncParams(func(req *FileStruct) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *FileStruct) error { return nil })
 	require.NoError(t, err)
 	require.Equal(t, FileFieldKindStdLib|FileFieldKindSlice, meta.forms["foo"].fileFieldKind)
 }
@@ -341,12 +509,12 @@ func TestFileFieldErrors(t *testing.T) {
 		File []FileHeader `form:"foo"`
 	}
 
-	meta, err := determineFuncParams(func(req *InvalidNonPointer) error { return nil })
+	meta, err := determineFuncParams(nil, func(req *InvalidNonPointer) error { return nil })
 	assert.Error(t, err)
 	assert.IsType(t, err, ErrInvalidFileField{})
 	assert.Nil(t, meta)
 
-	meta, err = determineFuncParams(func(req *InvalidSliceNonPointer) error { return nil })
+	meta, err = determineFuncParams(nil, func(req *InvalidSliceNonPointer) error { return nil })
 	assert.Error(t, err)
 	assert.IsType(t, err, ErrInvalidFileField{})
 	assert.Nil(t, meta)
@@ -4,10 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -20,6 +23,10 @@ type fileResponse struct {
 	file io.ReadCloser
 }
 
+type contentResponse struct {
+	content Content
+}
+
 type bytesResponse struct {
 	response []byte
 }
@@ -47,16 +54,20 @@ type Request struct {
 	// through it will contain the generated unique request ID for tracing.
 	Logger *zap.Logger
 
-	httpResponse   http.ResponseWriter
-	responseStatus int
-	response       interface{}
-	mux            *Mux
-	maxMemory      int64
-	requestID      string
-	statusSet      bool
-	acceptsMemo    []MediaType
-	setContentType bool
-	flushedHeaders bool
+	httpResponse      http.ResponseWriter
+	responseStatus    int
+	response          interface{}
+	mux               *Mux
+	maxMemory         int64
+	requestID         string
+	statusSet         bool
+	acceptsMemo       []MediaType
+	setContentType    bool
+	flushedHeaders    bool
+	strictNegotiation *bool
+	streamed          bool
+	panicStack        []StackFrame
+	abortNotFound     error
 }
 
 // NewRequest creates a new request with an empty mux. This method is intended
@@ -182,6 +193,40 @@ func (r *Request) RespondReader(file io.ReadCloser) {
 	r.response = &fileResponse{file: file}
 }
 
+// Content represents a seekable response body served through RespondContent.
+type Content struct {
+	// Name is used to infer the Content-Type of the response based on its
+	// extension, when no Content-Type is set. When no type can be inferred
+	// from Name, it is detected from the first bytes of Body.
+	Name string
+	// ModTime, when not zero, is emitted through the Last-Modified header
+	// and used to handle If-Modified-Since and If-Unmodified-Since headers.
+	ModTime time.Time
+	// ETag is emitted through the ETag header and used to handle
+	// If-None-Match, If-Match and If-Range headers. When empty and ModTime is
+	// not zero, a weak ETag is derived from ModTime and the size of Body.
+	ETag string
+	// Body represents the contents to be served. Content with a nil Body is
+	// handled by the error handler as a runtime error.
+	Body io.ReadSeeker
+}
+
+// RespondContent serves the provided Content with the same semantics of
+// http.ServeContent, handling Range requests (including multiple ranges) and
+// conditional requests. The response status is defined by the request's
+// headers, ignoring any value provided to SetStatus. Once the handler
+// function returns, contents are written to the response stream and Body is
+// closed in case it implements io.Closer.
+func (r *Request) RespondContent(content Content) {
+	r.response = &contentResponse{content: content}
+}
+
+// RespondFile is a convenience method for RespondContent, serving the provided
+// content with a given name and modification time.
+func (r *Request) RespondFile(name string, modtime time.Time, content io.ReadSeeker) {
+	r.RespondContent(Content{Name: name, ModTime: modtime, Body: content})
+}
+
 // RespondString returns a provided string to the client as the response body.
 // The contents will be sent to the client once the handler function returns.
 func (r *Request) RespondString(str string) {
@@ -280,7 +325,16 @@ func (r *Request) Abort() {
 // NotFound aborts the current request and returns a NotFound error to the
 // client.
 func (r *Request) NotFound() {
-	r.AbortError(errAbortNotFound)
+	// A new HTTPError is raised for each request, so custom error handlers
+	// can determine its status, and modify it without affecting other
+	// requests.
+	r.abortNotFound = NotFound("")
+	r.AbortError(r.abortNotFound)
+}
+
+// isAbortNotFound reports whether a given error was raised by NotFound.
+func (r *Request) isAbortNotFound(err error) bool {
+	return r.abortNotFound != nil && err == r.abortNotFound
 }
 
 // AbortError aborts the current request with a provided error.
@@ -302,6 +356,12 @@ func (r *Request) Respond(value interface{}) {
 	r.response = value
 }
 
+// SetStrictContentNegotiation overrides Mux.StrictContentNegotiation for this
+// request's response. See Mux.StrictContentNegotiation for details.
+func (r *Request) SetStrictContentNegotiation(strict bool) {
+	r.strictNegotiation = &strict
+}
+
 func (r *Request) setContentTypeNoOverride(value string) {
 	if !r.setContentType {
 		r.httpResponse.Header().Set("Content-Type", value)
@@ -335,6 +395,11 @@ func (r *Request) flushHeaders() {
 }
 
 func (r *Request) doRespond() {
+	if r.streamed {
+		// Response was already written by StreamEvents
+		return
+	}
+
 	if r.response == nil {
 		r.flushHeaders()
 		return
@@ -376,6 +441,8 @@ func (r *Request) doRespond() {
 			r.Logger.Error("raggett: Failed to close file stream", zap.Error(err))
 			return
 		}
+	case *contentResponse:
+		r.serveContent(v.content)
 	case *stringResponse:
 		r.setContentTypeNoOverride(plainTextContentTypeString)
 		r.flushHeaders()
@@ -392,6 +459,49 @@ func (r *Request) doRespond() {
 		}
 
 	default:
-		writeResponder(r, r.response)
+		strict := r.mux.StrictContentNegotiation
+		if r.strictNegotiation != nil {
+			strict = *r.strictNegotiation
+		}
+		writeNegotiatedResponder(r, r.response, strict)
+	}
+}
+
+func (r *Request) serveContent(c Content) {
+	if c.Body == nil {
+		r.mux.handleRuntimeError(makeError(errNilContentBody), nil, r)
+		return
+	}
+
+	if closer, ok := c.Body.(io.Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				r.Logger.Error("raggett: Failed to close content stream", zap.Error(err))
+			}
+		}()
+	}
+
+	etag := c.ETag
+	if etag == "" && !c.ModTime.IsZero() {
+		size, err := c.Body.Seek(0, io.SeekEnd)
+		if err == nil {
+			_, err = c.Body.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			r.Logger.Error("raggett: Failed to seek content stream", zap.Error(err))
+			r.SetStatus(http.StatusInternalServerError)
+			r.flushHeaders()
+			return
+		}
+		etag = fmt.Sprintf("W/\"%x-%x\"", c.ModTime.UnixNano(), size)
 	}
+	if etag != "" {
+		if !strings.HasPrefix(etag, "\"") && !strings.HasPrefix(etag, "W/\"") {
+			etag = strconv.Quote(etag)
+		}
+		r.httpResponse.Header().Set("ETag", etag)
+	}
+
+	r.flushedHeaders = true
+	http.ServeContent(r.httpResponse, r.HTTPRequest, c.Name, c.ModTime, c.Body)
 }
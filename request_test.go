@@ -158,7 +158,27 @@ func TestRequest_Abort(t *testing.T) {
 
 func TestRequest_NotFound(t *testing.T) {
 	r := NewRequest(nil, nil)
-	assert.PanicsWithError(t, errAbortNotFound.Error(), r.NotFound)
+	assert.PanicsWithError(t, "Not Found", r.NotFound)
+}
+
+func TestRequest_NotFoundNotShared(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.HandleError(func(err error, w http.ResponseWriter, r *Request) {
+		var httpErr *HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Empty(t, httpErr.Headers)
+		httpErr.WithHeader("X-Modified", "true")
+		w.WriteHeader(httpErr.Status)
+	})
+	mx.Get("/", func(r *EmptyRequest) error {
+		r.NotFound()
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		code, _ := doRequest(mx, "*/*", "GET", "/", nil)
+		assert.Equal(t, http.StatusNotFound, code)
+	}
 }
 
 func TestRequest_ClientAccepts(t *testing.T) {
@@ -539,3 +559,106 @@ func TestResponseWithCustomStatusNoBody(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 	assert.Empty(t, w.Body)
 }
+
+type closeTrackingReadSeeker struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeTrackingReadSeeker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRequest_RespondContent(t *testing.T) {
+	modTime := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	serve := func(headers map[string]string, content func() Content) (*httptest.ResponseRecorder, *closeTrackingReadSeeker) {
+		body := &closeTrackingReadSeeker{Reader: strings.NewReader("Hello, World!")}
+		mx := NewMux(zap.NewNop())
+		mx.Get("/", func(r *EmptyRequest) error {
+			c := content()
+			c.Body = body
+			r.RespondContent(c)
+			return nil
+		})
+		req := httptest.NewRequest("GET", "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, req)
+		return w, body
+	}
+
+	t.Run("full content", func(t *testing.T) {
+		w, body := serve(nil, func() Content { return Content{Name: "hello.txt", ModTime: modTime} })
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Hello, World!", w.Body.String())
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, modTime.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+		assert.Equal(t, fmt.Sprintf("W/\"%x-d\"", modTime.UnixNano()), w.Header().Get("ETag"))
+		assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+		assert.True(t, body.closed)
+	})
+
+	t.Run("sniffed content type", func(t *testing.T) {
+		w, _ := serve(nil, func() Content { return Content{} })
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Empty(t, w.Header().Get("ETag"))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		w, _ := serve(map[string]string{"Range": "bytes=0-4"}, func() Content { return Content{Name: "hello.txt"} })
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.Equal(t, "Hello", w.Body.String())
+		assert.Equal(t, "bytes 0-4/13", w.Header().Get("Content-Range"))
+	})
+
+	t.Run("multiple ranges", func(t *testing.T) {
+		w, _ := serve(map[string]string{"Range": "bytes=0-4,7-11"}, func() Content { return Content{Name: "hello.txt"} })
+		assert.Equal(t, http.StatusPartialContent, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges"))
+		assert.Contains(t, w.Body.String(), "Hello")
+		assert.Contains(t, w.Body.String(), "World")
+	})
+
+	t.Run("if-none-match", func(t *testing.T) {
+		w, _ := serve(map[string]string{"If-None-Match": `"v1"`}, func() Content { return Content{Name: "hello.txt", ETag: "v1"} })
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, `"v1"`, w.Header().Get("ETag"))
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("if-modified-since", func(t *testing.T) {
+		w, _ := serve(map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, func() Content {
+			return Content{Name: "hello.txt", ModTime: modTime, ETag: `W/"custom"`}
+		})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Equal(t, `W/"custom"`, w.Header().Get("ETag"))
+	})
+}
+
+func TestRequest_RespondContentNilBody(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r *EmptyRequest) error {
+		r.RespondContent(Content{Name: "hello.txt", ModTime: time.Now()})
+		return nil
+	})
+
+	code, body := doRequest(mx, "text/plain", "GET", "/", nil)
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.NotEmpty(t, body)
+}
+
+func TestRequest_RespondFile(t *testing.T) {
+	r := NewRequest(nil, nil)
+	modTime := time.Now()
+	body := strings.NewReader("foo")
+	r.RespondFile("foo.txt", modTime, body)
+	v, ok := r.response.(*contentResponse)
+	require.True(t, ok)
+	assert.Equal(t, "foo.txt", v.content.Name)
+	assert.Equal(t, modTime, v.content.ModTime)
+	assert.Equal(t, body, v.content.Body)
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 	"reflect"
 
 	"go.uber.org/zap"
@@ -59,6 +60,59 @@ type CustomResponder interface {
 }
 
 func writeResponder(r *Request, response interface{}) {
+	writeNegotiatedResponder(r, response, false)
+}
+
+var responderTypes = []reflect.Type{
+	reflect.TypeOf((*JSONResponder)(nil)).Elem(),
+	reflect.TypeOf((*XMLResponder)(nil)).Elem(),
+	reflect.TypeOf((*HTMLResponder)(nil)).Elem(),
+	reflect.TypeOf((*PlainTextResponder)(nil)).Elem(),
+	reflect.TypeOf((*BytesResponder)(nil)).Elem(),
+	reflect.TypeOf((*CustomResponder)(nil)).Elem(),
+}
+
+// isResponderType reports whether values of a given type can be provided to
+// writeNegotiatedResponder. Interface types are accepted, since values they
+// hold can only be inspected at runtime.
+func isResponderType(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	for _, r := range responderTypes {
+		if t.Implements(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiationError is handled by the error handler when strict content
+// negotiation fails. It wraps an HTTPError, so error handlers can determine
+// its status, and lists the representations available for the response.
+type negotiationError struct {
+	*HTTPError
+	offers []MediaType
+}
+
+func (e *negotiationError) Unwrap() error {
+	return e.HTTPError
+}
+
+// available returns the media types available for the response.
+func (e *negotiationError) available() []string {
+	available := make([]string, 0, len(e.offers))
+	for _, o := range e.offers {
+		available = append(available, o.Type())
+	}
+	return available
+}
+
+// writeNegotiatedResponder writes the representation of response negotiated
+// through the request's Accept header. When strict is set, a malformed Accept
+// header results in a 400, and an Accept header not matching any available
+// representation results in a 406, both handled by the Mux's error handler.
+func writeNegotiatedResponder(r *Request, response interface{}, strict bool) {
 	types := map[string]func(){}
 	var offers []MediaType
 	w := r.httpResponse
@@ -159,9 +213,17 @@ func writeResponder(r *Request, response interface{}) {
 
 	parsed, selected, media := NegotiateContentTypeWithMediaTypes(r.HTTPRequest, offers)
 
-	// TODO: Handle when parsed/selected is not ok
-
-	_, _ = parsed, selected
+	if strict && (!parsed || !selected) {
+		status, message := http.StatusNotAcceptable, "None of the available representations is acceptable."
+		if !parsed {
+			status, message = http.StatusBadRequest, "The provided Accept header is malformed."
+		}
+		r.mux.handleRuntimeError(&negotiationError{
+			HTTPError: NewHTTPError(status, message),
+			offers:    offers,
+		}, nil, r)
+		return
+	}
 
 	if !r.setContentType {
 		w.Header().Set("Content-Type", media.String())
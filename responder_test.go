@@ -3,10 +3,12 @@ package raggett
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -91,3 +93,131 @@ func TestNoOffers(t *testing.T) {
 	mx.ServeHTTP(w, req)
 	assert.Equal(t, 500, w.Code)
 }
+
+type negotiationResponderTest struct{}
+
+func (negotiationResponderTest) JSON() interface{} {
+	return map[string]string{"hello": "world"}
+}
+
+func (negotiationResponderTest) PlainText() string {
+	return "hello"
+}
+
+func TestStrictContentNegotiation(t *testing.T) {
+	type handler struct {
+		*Request
+	}
+
+	tests := []struct {
+		name     string
+		strict   bool
+		override *bool
+		accept   string
+		status   int
+	}{
+		{"Lenient no match", false, nil, "image/png", http.StatusOK},
+		{"Lenient malformed", false, nil, "text/", http.StatusOK},
+		{"Strict match", true, nil, "text/plain", http.StatusOK},
+		{"Strict wildcard", true, nil, "*/*", http.StatusOK},
+		{"Strict no match", true, nil, "image/png", http.StatusNotAcceptable},
+		{"Strict malformed", true, nil, "text/", http.StatusBadRequest},
+		{"Request override strict", false, boolPtr(true), "image/png", http.StatusNotAcceptable},
+		{"Request override lenient", true, boolPtr(false), "image/png", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mx := NewMux(zap.NewNop())
+			mx.StrictContentNegotiation = tt.strict
+			mx.Get("/", func(r *handler) error {
+				if tt.override != nil {
+					r.SetStrictContentNegotiation(*tt.override)
+				}
+				r.Respond(negotiationResponderTest{})
+				return nil
+			})
+			code, body := doRequest(mx, tt.accept, "GET", "/", nil)
+			assert.Equal(t, tt.status, code)
+			if tt.status == http.StatusNotAcceptable {
+				assert.Contains(t, body, "application/json")
+				assert.Contains(t, body, "text/plain")
+			}
+		})
+	}
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestTypedHandler(t *testing.T) {
+	type handler struct {
+		*Request
+		Fail bool `query:"fail"`
+		Nil  bool `query:"nil"`
+	}
+
+	mx := NewMux(zap.NewNop())
+	mx.Get("/", func(r handler) (*negotiationResponderTest, error) {
+		if r.Fail {
+			return &negotiationResponderTest{}, fmt.Errorf("boom")
+		}
+		if r.Nil {
+			return nil, nil
+		}
+		return &negotiationResponderTest{}, nil
+	})
+
+	code, body := doRequest(mx, "application/json", "GET", "/", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"hello": "world"}`, body)
+
+	code, body = doRequest(mx, "text/plain", "GET", "/", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "hello", body)
+
+	code, _ = doRequest(mx, "text/plain", "GET", "/?nil=true", nil)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	code, _ = doRequest(mx, "text/plain", "GET", "/?fail=true", nil)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestStrictContentNegotiationErrorHandling(t *testing.T) {
+	newMux := func() *Mux {
+		mx := NewMux(zap.NewNop())
+		mx.StrictContentNegotiation = true
+		mx.Get("/", func(r *EmptyRequest) error {
+			r.Respond(negotiationResponderTest{})
+			return nil
+		})
+		return mx
+	}
+
+	t.Run("Problem Details", func(t *testing.T) {
+		mx := newMux()
+		mx.ProblemDetails = true
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "image/png")
+		w := httptest.NewRecorder()
+		mx.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"available":["application/json","text/plain"]`)
+	})
+
+	t.Run("Error Handler", func(t *testing.T) {
+		mx := newMux()
+		var status int
+		mx.HandleError(func(err error, w http.ResponseWriter, r *Request) {
+			var httpErr *HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			status = httpErr.Status
+			w.WriteHeader(http.StatusTeapot)
+		})
+		code, _ := doRequest(mx, "text/", "GET", "/", nil)
+		assert.Equal(t, http.StatusTeapot, code)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
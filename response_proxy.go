@@ -1,10 +1,20 @@
 package raggett
 
-import "net/http"
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
 
+// responseProxy wraps a http.ResponseWriter, recording the status code and
+// amount of bytes written through it. Use wrap to obtain a http.ResponseWriter
+// exposing the same optional interfaces of the original writer.
 type responseProxy struct {
-	status   int
-	original http.ResponseWriter
+	status       int
+	bytesWritten int64
+	headersSent  bool
+	original     http.ResponseWriter
 }
 
 func (p *responseProxy) Header() http.Header {
@@ -12,10 +22,177 @@ func (p *responseProxy) Header() http.Header {
 }
 
 func (p *responseProxy) Write(bytes []byte) (int, error) {
-	return p.original.Write(bytes)
+	p.markHeadersSent(http.StatusOK)
+	n, err := p.original.Write(bytes)
+	p.bytesWritten += int64(n)
+	return n, err
 }
 
 func (p *responseProxy) WriteHeader(status int) {
-	p.status = status
+	if status >= 100 && status <= 199 && status != http.StatusSwitchingProtocols {
+		// Informational headers may be sent many times, and do not
+		// prevent the final status from being written.
+		p.original.WriteHeader(status)
+		return
+	}
+	p.markHeadersSent(status)
 	p.original.WriteHeader(status)
 }
+
+func (p *responseProxy) markHeadersSent(status int) {
+	if p.headersSent {
+		return
+	}
+	p.status = status
+	p.headersSent = true
+}
+
+func (p *responseProxy) flush() {
+	p.markHeadersSent(http.StatusOK)
+	p.original.(http.Flusher).Flush()
+}
+
+func (p *responseProxy) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return p.original.(http.Hijacker).Hijack()
+}
+
+func (p *responseProxy) readFrom(src io.Reader) (int64, error) {
+	p.markHeadersSent(http.StatusOK)
+	n, err := p.original.(io.ReaderFrom).ReadFrom(src)
+	p.bytesWritten += n
+	return n, err
+}
+
+func (p *responseProxy) push(target string, opts *http.PushOptions) error {
+	return p.original.(http.Pusher).Push(target, opts)
+}
+
+type flusherFunc func()
+
+func (f flusherFunc) Flush() { f() }
+
+type hijackerFunc func() (net.Conn, *bufio.ReadWriter, error)
+
+func (f hijackerFunc) Hijack() (net.Conn, *bufio.ReadWriter, error) { return f() }
+
+type readerFromFunc func(io.Reader) (int64, error)
+
+func (f readerFromFunc) ReadFrom(src io.Reader) (int64, error) { return f(src) }
+
+type pusherFunc func(string, *http.PushOptions) error
+
+func (f pusherFunc) Push(target string, opts *http.PushOptions) error { return f(target, opts) }
+
+// wrap returns a http.ResponseWriter backed by the proxy, implementing
+// exactly the same subset of http.Flusher, http.Hijacker, io.ReaderFrom and
+// http.Pusher implemented by the original writer.
+func (p *responseProxy) wrap() http.ResponseWriter {
+	_, isFlusher := p.original.(http.Flusher)
+	_, isHijacker := p.original.(http.Hijacker)
+	_, isReaderFrom := p.original.(io.ReaderFrom)
+	_, isPusher := p.original.(http.Pusher)
+
+	var (
+		f  = flusherFunc(p.flush)
+		h  = hijackerFunc(p.hijack)
+		rf = readerFromFunc(p.readFrom)
+		pu = pusherFunc(p.push)
+	)
+
+	switch {
+	case isFlusher && isHijacker && isReaderFrom && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{p, f, h, rf, pu}
+	case isFlusher && isHijacker && isReaderFrom:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{p, f, h, rf}
+	case isFlusher && isHijacker && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+			http.Pusher
+		}{p, f, h, pu}
+	case isFlusher && isReaderFrom && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+			http.Pusher
+		}{p, f, rf, pu}
+	case isHijacker && isReaderFrom && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+			http.Pusher
+		}{p, h, rf, pu}
+	case isFlusher && isHijacker:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Hijacker
+		}{p, f, h}
+	case isFlusher && isReaderFrom:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{p, f, rf}
+	case isFlusher && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+			http.Pusher
+		}{p, f, pu}
+	case isHijacker && isReaderFrom:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{p, h, rf}
+	case isHijacker && isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+			http.Pusher
+		}{p, h, pu}
+	case isReaderFrom && isPusher:
+		return struct {
+			http.ResponseWriter
+			io.ReaderFrom
+			http.Pusher
+		}{p, rf, pu}
+	case isFlusher:
+		return struct {
+			http.ResponseWriter
+			http.Flusher
+		}{p, f}
+	case isHijacker:
+		return struct {
+			http.ResponseWriter
+			http.Hijacker
+		}{p, h}
+	case isReaderFrom:
+		return struct {
+			http.ResponseWriter
+			io.ReaderFrom
+		}{p, rf}
+	case isPusher:
+		return struct {
+			http.ResponseWriter
+			http.Pusher
+		}{p, pu}
+	default:
+		return p
+	}
+}
@@ -0,0 +1,134 @@
+package raggett
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type plainResponseWriter struct {
+	header http.Header
+	status int
+	body   strings.Builder
+}
+
+func (w *plainResponseWriter) Header() http.Header         { return w.header }
+func (w *plainResponseWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *plainResponseWriter) WriteHeader(status int)      { w.status = status }
+
+type fullResponseWriter struct {
+	plainResponseWriter
+	flushed  bool
+	hijacked bool
+	pushed   string
+}
+
+func (w *fullResponseWriter) Flush() { w.flushed = true }
+func (w *fullResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+func (w *fullResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	return io.Copy(&w.body, src)
+}
+func (w *fullResponseWriter) Push(target string, _ *http.PushOptions) error {
+	w.pushed = target
+	return nil
+}
+
+func TestResponseProxyInterfaces(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		w := (&responseProxy{original: &plainResponseWriter{header: http.Header{}}}).wrap()
+		_, isFlusher := w.(http.Flusher)
+		_, isHijacker := w.(http.Hijacker)
+		_, isReaderFrom := w.(io.ReaderFrom)
+		_, isPusher := w.(http.Pusher)
+		assert.False(t, isFlusher)
+		assert.False(t, isHijacker)
+		assert.False(t, isReaderFrom)
+		assert.False(t, isPusher)
+	})
+
+	t.Run("recorder", func(t *testing.T) {
+		w := (&responseProxy{original: httptest.NewRecorder()}).wrap()
+		_, isFlusher := w.(http.Flusher)
+		_, isHijacker := w.(http.Hijacker)
+		_, isReaderFrom := w.(io.ReaderFrom)
+		_, isPusher := w.(http.Pusher)
+		assert.True(t, isFlusher)
+		assert.False(t, isHijacker)
+		assert.False(t, isReaderFrom)
+		assert.False(t, isPusher)
+	})
+
+	t.Run("full", func(t *testing.T) {
+		original := &fullResponseWriter{plainResponseWriter: plainResponseWriter{header: http.Header{}}}
+		proxy := &responseProxy{original: original}
+		w := proxy.wrap()
+
+		w.(http.Flusher).Flush()
+		assert.True(t, original.flushed)
+
+		_, _, err := w.(http.Hijacker).Hijack()
+		assert.NoError(t, err)
+		assert.True(t, original.hijacked)
+
+		assert.NoError(t, w.(http.Pusher).Push("/style.css", nil))
+		assert.Equal(t, "/style.css", original.pushed)
+
+		n, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("hello"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+		assert.Equal(t, int64(5), proxy.bytesWritten)
+		assert.True(t, proxy.headersSent)
+		assert.Equal(t, http.StatusOK, proxy.status)
+	})
+}
+
+func TestResponseProxyRecordsWrites(t *testing.T) {
+	original := &plainResponseWriter{header: http.Header{}}
+	proxy := &responseProxy{original: original}
+	w := proxy.wrap()
+	assert.False(t, proxy.headersSent)
+
+	w.WriteHeader(http.StatusEarlyHints)
+	assert.False(t, proxy.headersSent)
+
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write([]byte("hello"))
+	_, _ = w.Write([]byte(", world"))
+	assert.True(t, proxy.headersSent)
+	assert.Equal(t, http.StatusCreated, proxy.status)
+	assert.Equal(t, int64(12), proxy.bytesWritten)
+	assert.Equal(t, "hello, world", original.body.String())
+}
+
+func TestRequestFinishedLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	mx := NewMux(zap.New(core))
+	mx.Get("/", func(r *EmptyRequest) error {
+		r.RespondString("hello")
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+
+	entries := logs.FilterMessage("Request finished").All()
+	if assert.Len(t, entries, 1) {
+		fields := entries[0].ContextMap()
+		assert.Equal(t, int64(200), fields["status"])
+		assert.Equal(t, int64(5), fields["bytes_written"])
+		assert.Equal(t, true, fields["headers_sent"])
+	}
+}
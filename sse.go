@@ -0,0 +1,105 @@
+package raggett
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// Event represents a single Server-Sent Event sent through
+// Request.StreamEvents.
+type Event struct {
+	// ID sets the event's identifier. Clients provide the last received ID
+	// through the Last-Event-ID header when reconnecting. Empty IDs are not
+	// sent.
+	ID string
+	// Name indicates the event's type. When empty, clients dispatch it as a
+	// "message" event.
+	Name string
+	// Data contains the event's payload. Multi-line values, separated by CRLF,
+	// CR or LF, are sent as multiple data lines. Line breaks are removed from
+	// ID and Name.
+	Data string
+	// Retry, when greater than zero, instructs clients to wait the provided
+	// duration before reconnecting.
+	Retry time.Duration
+}
+
+func (e Event) encode() string {
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + stripNewlines(e.ID) + "\n")
+	}
+	if e.Name != "" {
+		b.WriteString("event: " + stripNewlines(e.Name) + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	if e.Data != "" || (e.ID == "" && e.Name == "" && e.Retry <= 0) {
+		// CRLF, CR and LF all terminate lines in event streams, so each of
+		// them starts a new data line.
+		data := strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(e.Data)
+		for _, line := range strings.Split(data, "\n") {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// LastEventID returns the value of the Last-Event-ID header provided by
+// clients reconnecting to an event stream, or an empty string in case it is
+// absent.
+func (r *Request) LastEventID() string {
+	return r.HTTPRequest.Header.Get("Last-Event-ID")
+}
+
+// StreamEvents responds to the client with a text/event-stream, invoking fn
+// with a send function used to emit events. Each event is flushed to the
+// client as soon as it is sent. Headers are sent immediately, so the status
+// and headers of the response must be set before calling this method, and no
+// other response can be provided afterwards.
+// Once the request's context is cancelled (for instance, when the client
+// disconnects), send returns the context's error. StreamEvents returns the
+// error returned by fn, unless it is caused by the context cancellation, even
+// when wrapped.
+func (r *Request) StreamEvents(fn func(send func(Event) error) error) error {
+	ctx := r.Context()
+	r.SetHeader("Content-Type", eventStreamContentType)
+	r.SetHeader("Cache-Control", "no-cache")
+	r.SetStatus(http.StatusOK)
+	r.flushHeaders()
+	r.streamed = true
+
+	flusher, _ := r.httpResponse.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	err := fn(func(e Event) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := r.httpResponse.Write([]byte(e.encode())); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package raggett
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEventEncode(t *testing.T) {
+	assert.Equal(t, "data: hello\n\n", Event{Data: "hello"}.encode())
+	assert.Equal(t, "id: 1\nevent: update\nretry: 1500\ndata: a\ndata: b\n\n", Event{
+		ID:    "1",
+		Name:  "update",
+		Data:  "a\r\nb",
+		Retry: 1500 * time.Millisecond,
+	}.encode())
+	assert.Equal(t, "retry: 3000\n\n", Event{Retry: 3 * time.Second}.encode())
+	assert.Equal(t, "data: \n\n", Event{}.encode())
+
+	// Lone CRs must not allow injecting fields into the stream.
+	assert.Equal(t, "id: 1id: 2\nevent: updateevent: x\ndata: a\ndata: event: x\ndata: id: 3\n\n", Event{
+		ID:   "1\rid: 2",
+		Name: "update\revent: x",
+		Data: "a\revent: x\rid: 3",
+	}.encode())
+}
+
+func TestStreamEvents(t *testing.T) {
+	mx := NewMux(zap.NewNop())
+	mx.Get("/events", func(r *EmptyRequest) error {
+		lastID := r.LastEventID()
+		return r.StreamEvents(func(send func(Event) error) error {
+			if err := send(Event{ID: lastID + "1", Data: "first"}); err != nil {
+				return err
+			}
+			return send(Event{ID: lastID + "2", Name: "done", Data: "second"})
+		})
+	})
+
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Last-Event-ID", "a")
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.Empty(t, w.Header().Get("Connection"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "id: a1\ndata: first\n\nid: a2\nevent: done\ndata: second\n\n", w.Body.String())
+}
+
+func TestStreamEventsCancelled(t *testing.T) {
+	var handlerErr, sendErr error
+	mx := NewMux(zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	mx.Get("/events", func(r *EmptyRequest) error {
+		handlerErr = r.StreamEvents(func(send func(Event) error) error {
+			require.NoError(t, send(Event{Data: "first"}))
+			cancel()
+			sendErr = send(Event{Data: "second"})
+			return sendErr
+		})
+		return handlerErr
+	})
+
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.ErrorIs(t, sendErr, context.Canceled)
+	assert.NoError(t, handlerErr)
+	assert.Equal(t, "data: first\n\n", w.Body.String())
+}
+
+func TestStreamEventsWrappedCancellation(t *testing.T) {
+	var handlerErr error
+	mx := NewMux(zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	mx.Get("/events", func(r *EmptyRequest) error {
+		handlerErr = r.StreamEvents(func(send func(Event) error) error {
+			cancel()
+			if err := send(Event{Data: "first"}); err != nil {
+				return fmt.Errorf("sending update: %w", err)
+			}
+			return nil
+		})
+		return handlerErr
+	})
+
+	req := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+	mx.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.NoError(t, handlerErr)
+	assert.Empty(t, w.Body.String())
+}
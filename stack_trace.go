@@ -19,7 +19,7 @@ func makeError(err error) error {
 func getStack(skip int) []StackFrame {
 	pcs := make([]uintptr, 255)
 	count := runtime.Callers(skip+1, pcs)
-	frames := runtime.CallersFrames(pcs)
+	frames := runtime.CallersFrames(pcs[:count])
 	trace := make([]StackFrame, 0, count)
 	for {
 		frame, more := frames.Next()
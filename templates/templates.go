@@ -4,6 +4,9 @@ import (
 	"bytes"
 	_ "embed"
 	html "html/template"
+	"io"
+	"sort"
+	"strings"
 	text "text/template"
 )
 
@@ -20,6 +23,8 @@ const (
 	NotFoundErrorText              = "not_found.txt"
 	NotFoundErrorConstrainedHTML   = "not_found_constrained.html"
 	NotFoundErrorConstrainedText   = "not_found_constrained.txt"
+	NotAcceptableErrorHTML         = "not_acceptable.html"
+	NotAcceptableErrorText         = "not_acceptable.txt"
 )
 
 //go:embed error.html
@@ -58,13 +63,23 @@ var notFoundErrorConstrainedHTMLString string
 //go:embed not_found_constrained.txt
 var notFoundErrorConstrainedTextString string
 
+//go:embed not_acceptable.html
+var notAcceptableErrorHTMLString string
+
+//go:embed not_acceptable.txt
+var notAcceptableErrorTextString string
+
+// Executor renders a template with the provided data.
 type Executor func(data interface{}) (string, error)
 
-func mustLoadHTMLTemplate(data string) Executor {
-	t, err := html.New("").Parse(data)
-	if err != nil {
-		panic("raggett: Failed loading template:" + err.Error())
-	}
+// Template represents a parsed template, such as *html/template.Template and
+// *text/template.Template.
+type Template interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// NewExecutor returns an Executor rendering a given Template.
+func NewExecutor(t Template) Executor {
 	return func(data interface{}) (string, error) {
 		var w bytes.Buffer
 		if err := t.Execute(&w, data); err != nil {
@@ -74,18 +89,20 @@ func mustLoadHTMLTemplate(data string) Executor {
 	}
 }
 
+func mustLoadHTMLTemplate(data string) Executor {
+	t, err := html.New("").Parse(data)
+	if err != nil {
+		panic("raggett: Failed loading template:" + err.Error())
+	}
+	return NewExecutor(t)
+}
+
 func mustLoadTextTemplate(data string) Executor {
 	t, err := text.New("").Parse(data)
 	if err != nil {
 		panic("raggett: Failed loading template:" + err.Error())
 	}
-	return func(data interface{}) (string, error) {
-		var w bytes.Buffer
-		if err := t.Execute(&w, data); err != nil {
-			return "", err
-		}
-		return w.String(), nil
-	}
+	return NewExecutor(t)
 }
 
 var templates = map[string]Executor{
@@ -100,9 +117,36 @@ var templates = map[string]Executor{
 	NotFoundErrorHTML:              mustLoadHTMLTemplate(notFoundErrorHTMLString),
 	NotFoundErrorText:              mustLoadTextTemplate(notFoundErrorTextString),
 	NotFoundErrorConstrainedHTML:   mustLoadHTMLTemplate(notFoundErrorConstrainedHTMLString),
-	NotFoundErrorConstrainedText:   mustLoadHTMLTemplate(notFoundErrorConstrainedTextString),
+	NotFoundErrorConstrainedText:   mustLoadTextTemplate(notFoundErrorConstrainedTextString),
+	NotAcceptableErrorHTML:         mustLoadHTMLTemplate(notAcceptableErrorHTMLString),
+	NotAcceptableErrorText:         mustLoadTextTemplate(notAcceptableErrorTextString),
+}
+
+// Exists reports whether name is the name of a template provided by this
+// package.
+func Exists(name string) bool {
+	_, ok := templates[name]
+	return ok
+}
+
+// Names returns the names of all templates provided by this package, in
+// alphabetical order.
+func Names() []string {
+	names := make([]string, 0, len(templates))
+	for k := range templates {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsHTML reports whether a given template name refers to an HTML template.
+func IsHTML(name string) bool {
+	return strings.HasSuffix(name, ".html")
 }
 
+// TemplateNamed returns the Executor for a given template name. Panics in case
+// the name is unknown.
 func TemplateNamed(name string) Executor {
 	v, ok := templates[name]
 	if !ok {